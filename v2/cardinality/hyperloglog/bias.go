@@ -0,0 +1,42 @@
+package hyperloglog
+
+import "math"
+
+// below these cardinalities linear counting beats the bias-corrected estimate (HLL++ appendix)
+var thresholdData = [15]float64{
+	10, 20, 40, 80, 220, 400, 900, 1800, 3100, 6500, 11500, 20000, 50000, 120000, 350000,
+}
+
+const biasNeighbors = 6
+
+// mean bias of the k nearest raw estimates in the empirical table
+// the published raw estimates are noisy and not strictly increasing, so every point is compared
+func estimateBias(estimate float64, p uint) float64 {
+	rawEstimates := rawEstimateData[p-MinPrecision]
+	biases := biasData[p-MinPrecision]
+
+	// nearest[:n] sorted by distance
+	var nearest [biasNeighbors]int
+	n := 0
+	distance := func(i int) float64 { return math.Abs(rawEstimates[i] - estimate) }
+	for i := range rawEstimates {
+		j := n
+		if n < biasNeighbors {
+			n++
+		} else if distance(i) >= distance(nearest[n-1]) {
+			continue
+		} else {
+			j = n - 1
+		}
+		for ; j > 0 && distance(i) < distance(nearest[j-1]); j-- {
+			nearest[j] = nearest[j-1]
+		}
+		nearest[j] = i
+	}
+
+	sum := 0.0
+	for _, i := range nearest[:n] {
+		sum += biases[i]
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,182 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"unsafe"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// HyperLogLog (Flajolet et al., 2007)
+// 2^p registers keep max rho (position of leftmost 1-bit) of hashes routed to them by p MSBs
+// cardinality = alphaM * m^2 / sum(2^-M[j]), std error ~ 1.04 / sqrt(m)
+// HLL++ mode starts sparse (see sparse.go) and corrects mid-range bias empirically
+type HyperLogLog[T hasher.HashOutType] struct {
+	p        uint
+	m        uint
+	hashBits uint
+	alphaM   float64
+	plusPlus bool
+	sparse   *sparseList // nil once dense
+	r        register.Register
+	h        hasher.HashGenerator[T]
+}
+
+const (
+	MinPrecision = 4
+	MaxPrecision = 18
+	// 6 bits hold rho up to 63, enough for 64-bit hashes
+	RegisterBitWidth = 6
+)
+
+const (
+	InvalidPrecisionMsg = "invalid precision (%v not in [%v, %v])"
+)
+
+func HyperLogLogAlphaM(m uint) float64 {
+	switch {
+	case m <= 16:
+		return 0.673
+	case m <= 32:
+		return 0.697
+	case m <= 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+func newHyperLogLog[T hasher.HashOutType](p uint, plusPlus bool, h hasher.HashGenerator[T]) (*HyperLogLog[T], error) {
+	if p < MinPrecision || p > MaxPrecision {
+		return nil, fmt.Errorf(InvalidPrecisionMsg, p, MinPrecision, MaxPrecision)
+	}
+	var genericRef T
+	m := uint(1) << p
+	hll := &HyperLogLog[T]{
+		p:        p,
+		m:        m,
+		hashBits: uint(8 * unsafe.Sizeof(genericRef)),
+		alphaM:   HyperLogLogAlphaM(m),
+		plusPlus: plusPlus,
+		h:        h,
+	}
+	if plusPlus {
+		hll.sparse = newSparseList(hll.sparseLimit())
+		return hll, nil
+	}
+	return hll, hll.allocDense()
+}
+
+func (c *HyperLogLog[T]) allocDense() error {
+	r, err := register.NewRegister(c.m, RegisterBitWidth)
+	if err != nil {
+		return err
+	}
+	c.r = r
+	return nil
+}
+
+func (c *HyperLogLog[T]) Precision() uint  { return c.p }
+func (c *HyperLogLog[T]) HashAttr() string { return c.h.String() }
+func (c *HyperLogLog[T]) IsPlusPlus() bool { return c.plusPlus }
+func (c *HyperLogLog[T]) IsSparse() bool   { return c.sparse != nil }
+
+func (c *HyperLogLog[T]) ByteSize() uint {
+	if c.sparse != nil {
+		return c.sparse.byteSize()
+	}
+	return register.GetByteSize(c.r)
+}
+
+func (c *HyperLogLog[T]) StdError() float64 {
+	return 1.04 / math.Sqrt(float64(c.m))
+}
+
+// register index from p MSBs, rho from the rest
+func (c *HyperLogLog[T]) indexRho(hash uint64) (index uint, rho uint) {
+	hash <<= 64 - c.hashBits
+	index = uint(hash >> (64 - c.p))
+	w := hash << c.p
+	rho = uint(bits.LeadingZeros64(w)) + 1
+	if maxRho := c.hashBits - c.p + 1; rho > maxRho {
+		// w = 0
+		rho = maxRho
+	}
+	return index, rho
+}
+
+func (c *HyperLogLog[T]) Add(item []byte) error {
+	hashes, err := c.h.GenerateHash(item, 0, math.MaxUint, 1)
+	if err != nil {
+		return err
+	}
+	if c.sparse != nil {
+		return c.addSparse(uint64(hashes[0]))
+	}
+	return c.updateDense(c.indexRho(uint64(hashes[0])))
+}
+
+func (c *HyperLogLog[T]) updateDense(index, rho uint) error {
+	current, err := c.r.Read(index)
+	if err != nil {
+		return err
+	}
+	if rho > current {
+		_, err = c.r.Write(index, rho)
+	}
+	return err
+}
+
+func (c *HyperLogLog[T]) Estimate() float64 {
+	if c.sparse != nil {
+		return c.estimateSparse()
+	}
+
+	sum := 0.0
+	zeros := uint(0)
+	for j := uint(0); j < c.m; j++ {
+		v, _ := c.r.Read(j)
+		sum += math.Ldexp(1, -int(v))
+		if v == 0 {
+			zeros++
+		}
+	}
+	m := float64(c.m)
+	estimate := c.alphaM * m * m / sum
+
+	if c.plusPlus {
+		return c.estimatePlusPlus(estimate, zeros)
+	}
+
+	if estimate <= 2.5*m && zeros > 0 {
+		// small range correction: linear counting over empty registers
+		return m * math.Log(m/float64(zeros))
+	}
+
+	hashSpace := math.Ldexp(1, int(c.hashBits))
+	if estimate > hashSpace/30 {
+		// large range correction: hash collisions become likely
+		return -hashSpace * math.Log(1-estimate/hashSpace)
+	}
+	return estimate
+}
+
+func (c *HyperLogLog[T]) Cardinality() uint {
+	return uint(math.Round(c.Estimate()))
+}
+
+func (c *HyperLogLog[T]) estimatePlusPlus(estimate float64, zeros uint) float64 {
+	m := float64(c.m)
+	if estimate <= 5*m {
+		estimate -= estimateBias(estimate, c.p)
+	}
+	if zeros > 0 {
+		linearCount := m * math.Log(m/float64(zeros))
+		if linearCount <= thresholdData[c.p-MinPrecision] {
+			return linearCount
+		}
+	}
+	return estimate
+}
@@ -0,0 +1,49 @@
+package hyperloglog
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type HyperLogLogBuilder[T hasher.HashOutType] struct {
+	p        uint
+	plusPlus bool
+	h        hasher.HashGenerator[T]
+	hashErr  error
+}
+
+func NewHyperLogLogBuilder[T hasher.HashOutType]() *HyperLogLogBuilder[T] {
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &HyperLogLogBuilder[T]{
+		p: 14,
+		h: *defaultHasher,
+	}
+}
+
+// 2^p registers
+func (b *HyperLogLogBuilder[T]) SetPrecision(p uint) *HyperLogLogBuilder[T] {
+	b.p = p
+	return b
+}
+
+// HLL++: sparse representation at p' = 25 and empirical bias correction
+func (b *HyperLogLogBuilder[T]) SetPlusPlus(plusPlus bool) *HyperLogLogBuilder[T] {
+	b.plusPlus = plusPlus
+	return b
+}
+
+func (b *HyperLogLogBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *HyperLogLogBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *HyperLogLogBuilder[T]) Build() (*HyperLogLog[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newHyperLogLog(b.p, b.plusPlus, b.h)
+}
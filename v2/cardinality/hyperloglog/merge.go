@@ -0,0 +1,147 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+const (
+	MismatchedSketchMsg = "mismatched sketches: (p = %v, plusPlus = %v, hash = %v) != (p = %v, plusPlus = %v, hash = %v)"
+	NoSketchMsg         = "no sketch to estimate"
+	TooManySketchesMsg  = "too many sketches to intersect (%v > %v)"
+)
+
+// inclusion-exclusion takes 2^n - 1 unions of n sketches
+const MaxIntersectionSketches = 10
+
+// absolute 1-sigma error next to the estimate
+type CardinalityEstimate struct {
+	Value    float64
+	StdError float64
+}
+
+func (c *HyperLogLog[T]) checkMergeable(other *HyperLogLog[T]) error {
+	// classic and HLL++ registers agree, but their estimators and sparse encoding don't
+	if c.p != other.p || c.plusPlus != other.plusPlus || !c.h.Compatible(other.h) {
+		return fmt.Errorf(MismatchedSketchMsg, c.p, c.plusPlus, c.h.String(), other.p, other.plusPlus, other.h.String())
+	}
+	return nil
+}
+
+// register-wise max, both sketches must share precision, variant and hash function
+// other is only read
+func (c *HyperLogLog[T]) Merge(other *HyperLogLog[T]) error {
+	if err := c.checkMergeable(other); err != nil {
+		return err
+	}
+
+	if c.sparse != nil && other.sparse != nil {
+		// flushed whenever the buffer is full, growing it would count against the sparse budget
+		other.sparse.each(func(entry uint32) {
+			c.sparse.pending = append(c.sparse.pending, entry)
+			if len(c.sparse.pending) == cap(c.sparse.pending) {
+				c.sparse.flush()
+			}
+		})
+		c.sparse.flush()
+		if c.sparse.byteSize() > c.sparseLimit() {
+			return c.toDense()
+		}
+		return nil
+	}
+
+	if c.sparse != nil {
+		if err := c.toDense(); err != nil {
+			return err
+		}
+	}
+
+	if other.sparse != nil {
+		var err error
+		other.sparse.each(func(entry uint32) {
+			if updateErr := c.updateDense(c.denseEntry(entry)); updateErr != nil {
+				err = updateErr
+			}
+		})
+		return err
+	}
+
+	for j := uint(0); j < c.m; j++ {
+		v, _ := other.r.Read(j)
+		if err := c.updateDense(j, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *HyperLogLog[T]) Clone() *HyperLogLog[T] {
+	clone, _ := newHyperLogLog(c.p, c.plusPlus, c.h)
+	clone.Merge(c)
+	return clone
+}
+
+func (c *HyperLogLog[T]) estimateWithError() CardinalityEstimate {
+	estimate := c.Estimate()
+	return CardinalityEstimate{estimate, c.StdError() * estimate}
+}
+
+func union[T hasher.HashOutType](sketches []*HyperLogLog[T]) (*HyperLogLog[T], error) {
+	merged := sketches[0].Clone()
+	for _, sketch := range sketches[1:] {
+		if err := merged.Merge(sketch); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// |A u B u ...| from merged registers, error is the one of a single sketch of the same precision
+func UnionEstimate[T hasher.HashOutType](a *HyperLogLog[T], others ...*HyperLogLog[T]) (CardinalityEstimate, error) {
+	merged, err := union(append([]*HyperLogLog[T]{a}, others...))
+	if err != nil {
+		return CardinalityEstimate{}, err
+	}
+	return merged.estimateWithError(), nil
+}
+
+// |A n B n ...| by inclusion-exclusion over unions of every non-empty subset
+// union errors are treated as independent, so std error = sqrt(sum of squared union errors)
+// NOTE: the absolute error scales with the union, small intersections of large sets are unreliable
+func IntersectionEstimate[T hasher.HashOutType](a *HyperLogLog[T], others ...*HyperLogLog[T]) (CardinalityEstimate, error) {
+	sketches := append([]*HyperLogLog[T]{a}, others...)
+	if len(sketches) > MaxIntersectionSketches {
+		return CardinalityEstimate{}, fmt.Errorf(TooManySketchesMsg, len(sketches), MaxIntersectionSketches)
+	}
+	for _, sketch := range sketches[1:] {
+		if err := a.checkMergeable(sketch); err != nil {
+			return CardinalityEstimate{}, err
+		}
+	}
+
+	value, variance := 0.0, 0.0
+	subset := make([]*HyperLogLog[T], 0, len(sketches))
+	for mask := 1; mask < 1<<len(sketches); mask++ {
+		subset = subset[:0]
+		for i, sketch := range sketches {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, sketch)
+			}
+		}
+		merged, err := union(subset)
+		if err != nil {
+			return CardinalityEstimate{}, err
+		}
+		estimate := merged.estimateWithError()
+		if len(subset)%2 == 1 {
+			value += estimate.Value
+		} else {
+			value -= estimate.Value
+		}
+		variance += estimate.StdError * estimate.StdError
+	}
+
+	return CardinalityEstimate{math.Max(0, value), math.Sqrt(variance)}, nil
+}
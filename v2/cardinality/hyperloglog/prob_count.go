@@ -1,17 +1,28 @@
 package hyperloglog
 
 import (
+	"fmt"
 	"math"
 	"math/bits"
 
 	"github.com/nnurry/probabilistics/v2/utilities/hasher"
 )
 
+const MismatchedCounterMsg = "mismatched counters: (hash = %v) != (hash = %v)"
+
 type ProbCounter struct {
 	pMax uint64
 	h    hasher.HashGenerator[uint64]
 }
 
+func NewProbCounter(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) (*ProbCounter, error) {
+	h, err := hasher.NewHashGenerator[uint64](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProbCounter{h: *h}, nil
+}
+
 func (c *ProbCounter) Add(item []byte) error {
 	hashes, _ := c.h.GenerateHash(item, 0, math.MaxUint64, 1)
 	p := uint64(bits.TrailingZeros64(hashes[0]) + 1)
@@ -21,6 +32,17 @@ func (c *ProbCounter) Add(item []byte) error {
 	return nil
 }
 
+// max of pMax, both counters must share hash function
+func (c *ProbCounter) Merge(other *ProbCounter) error {
+	if !c.h.Compatible(other.h) {
+		return fmt.Errorf(MismatchedCounterMsg, c.h.String(), other.h.String())
+	}
+	if c.pMax < other.pMax {
+		c.pMax = other.pMax
+	}
+	return nil
+}
+
 func (c *ProbCounter) Cardinality() uint {
 	if c.pMax == 0 {
 		return 0
@@ -0,0 +1,160 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+// HLL++ sparse representation (Heule et al., 2013)
+// while few registers are set, keep (index, rho) pairs at higher precision p' in a sorted list
+// instead of allocating 2^p dense registers, linear counting over 2^p' is near exact there
+//
+// entry = idx' (p' bits) << 6 | rho' (6 bits), rho' is rho of the hash bits after the p' MSBs
+// list is delta + varint encoded, new entries go to an unsorted buffer that is merged when full
+const (
+	SparsePrecision = 25
+	sparseRhoBits   = 6
+)
+
+type sparseList struct {
+	data    []byte   // delta + varint encoded sorted entries, 1 per idx'
+	size    uint     // decoded entries in data
+	pending []uint32 // unsorted, not yet merged entries
+}
+
+func (c *HyperLogLog[T]) sparseEntry(hash uint64) uint32 {
+	hash <<= 64 - c.hashBits
+	index := hash >> (64 - SparsePrecision)
+	rho := uint64(bits.LeadingZeros64(hash<<SparsePrecision)) + 1
+	if maxRho := uint64(c.hashBits - SparsePrecision + 1); rho > maxRho {
+		rho = maxRho
+	}
+	return uint32(index<<sparseRhoBits | rho)
+}
+
+// dense (index, rho) of a sparse entry
+func (c *HyperLogLog[T]) denseEntry(entry uint32) (index uint, rho uint) {
+	sparseIndex := uint(entry >> sparseRhoBits)
+	extraBits := SparsePrecision - c.p
+	index = sparseIndex >> extraBits
+	// bits between p and p' belong to the dense w, rho is found there unless they're all 0
+	extra := sparseIndex & (1<<extraBits - 1)
+	if extra != 0 {
+		rho = uint(bits.LeadingZeros(extra)) - (uint(bits.UintSize) - extraBits) + 1
+	} else {
+		rho = uint(entry&(1<<sparseRhoBits-1)) + extraBits
+	}
+	return index, rho
+}
+
+func decodeSparse(data []byte, fn func(entry uint32)) {
+	entry := uint32(0)
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		data = data[n:]
+		entry += uint32(delta)
+		fn(entry)
+	}
+}
+
+// encoded and pending entries, unsorted, the list itself is left as is
+func (s *sparseList) each(fn func(entry uint32)) {
+	// fn may append to this very list when a sketch is merged into itself
+	pending := slices.Clone(s.pending)
+	decodeSparse(s.data, fn)
+	for _, entry := range pending {
+		fn(entry)
+	}
+}
+
+// merges pending entries into encoded list, keeps max rho' per idx'
+func (s *sparseList) flush() {
+	if len(s.pending) == 0 {
+		return
+	}
+	sort.Slice(s.pending, func(i, j int) bool { return s.pending[i] < s.pending[j] })
+
+	merged := make([]byte, 0, len(s.data)+len(s.pending)*binary.MaxVarintLen32)
+	prev, size := uint32(0), uint(0)
+	last, hasLast := uint32(0), false
+	emit := func(entry uint32) {
+		// same idx' -> entries are sorted so the later one has the higher rho'
+		if hasLast && last>>sparseRhoBits != entry>>sparseRhoBits {
+			merged = binary.AppendUvarint(merged, uint64(last-prev))
+			prev = last
+			size++
+		}
+		last, hasLast = entry, true
+	}
+
+	i := 0
+	decodeSparse(s.data, func(entry uint32) {
+		for i < len(s.pending) && s.pending[i] < entry {
+			emit(s.pending[i])
+			i++
+		}
+		emit(entry)
+	})
+	for ; i < len(s.pending); i++ {
+		emit(s.pending[i])
+	}
+	if hasLast {
+		merged = binary.AppendUvarint(merged, uint64(last-prev))
+		size++
+	}
+
+	s.data = merged
+	s.size = size
+	s.pending = s.pending[:0]
+}
+
+func (s *sparseList) byteSize() uint {
+	return uint(len(s.data) + 4*cap(s.pending))
+}
+
+// sparse list stops paying off once it outgrows the dense registers
+func (c *HyperLogLog[T]) sparseLimit() uint {
+	return c.m * RegisterBitWidth / 8
+}
+
+// pending buffer takes up to 1/4 of the sparse budget
+func newSparseList(limit uint) *sparseList {
+	return &sparseList{pending: make([]uint32, 0, max(1, limit/16))}
+}
+
+func (c *HyperLogLog[T]) addSparse(hash uint64) error {
+	c.sparse.pending = append(c.sparse.pending, c.sparseEntry(hash))
+	if len(c.sparse.pending) < cap(c.sparse.pending) {
+		return nil
+	}
+	c.sparse.flush()
+	if c.sparse.byteSize() > c.sparseLimit() {
+		return c.toDense()
+	}
+	return nil
+}
+
+func (c *HyperLogLog[T]) toDense() error {
+	if err := c.allocDense(); err != nil {
+		return err
+	}
+	c.sparse.flush()
+	var err error
+	decodeSparse(c.sparse.data, func(entry uint32) {
+		index, rho := c.denseEntry(entry)
+		if writeErr := c.updateDense(index, rho); writeErr != nil {
+			err = writeErr
+		}
+	})
+	c.sparse = nil
+	return err
+}
+
+func (c *HyperLogLog[T]) estimateSparse() float64 {
+	c.sparse.flush()
+	mPrime := math.Ldexp(1, SparsePrecision)
+	return mPrime * math.Log(mPrime/(mPrime-float64(c.sparse.size)))
+}
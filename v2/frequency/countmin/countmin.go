@@ -0,0 +1,222 @@
+package countmin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// Count-Min sketch (Cormode & Muthukrishnan, 2005)
+// d rows of w counters, an item increments 1 counter per row and is estimated by the row minimum
+// estimate <= true count + eps * N with probability 1 - delta for w = e / eps, d = ln(1 / delta)
+type CountMinSketch[T hasher.HashOutType] struct {
+	width     uint
+	depth     uint
+	total     uint // N, sum of all added counts
+	update    UpdateMode
+	estimator Estimator
+	r         register.Register
+	h         hasher.HashGenerator[T]
+}
+
+type UpdateMode uint8
+
+const (
+	// increment every row counter
+	StandardUpdate UpdateMode = iota
+	// only raise counters up to (current estimate + n), never below the true count (Estan & Varghese, 2002)
+	ConservativeUpdate
+)
+
+type Estimator uint8
+
+const (
+	// row minimum
+	MinEstimator Estimator = iota
+	// median of per-row counters minus their expected noise (N - c) / (w - 1), capped by row minimum (Deng & Rafiei, 2007)
+	CountMeanMinEstimator
+)
+
+const (
+	InvalidDimensionMsg = "invalid dimension (width = %v, depth = %v)"
+	MismatchedSketchMsg = "mismatched sketches: (width = %v, depth = %v, bit width = %v, hash = %v) != (width = %v, depth = %v, bit width = %v, hash = %v)"
+)
+
+func CountMinEstimateParams(epsilon float64, delta float64) (width, depth uint) {
+	width = uint(math.Ceil(math.E / epsilon))
+	depth = uint(math.Ceil(math.Log(1 / delta)))
+	return width, depth
+}
+
+func newCountMinSketch[T hasher.HashOutType](width, depth, counterBits uint, update UpdateMode, estimator Estimator, h hasher.HashGenerator[T]) (*CountMinSketch[T], error) {
+	if width == 0 || depth == 0 {
+		return nil, fmt.Errorf(InvalidDimensionMsg, width, depth)
+	}
+	r, err := register.NewRegister(width*depth, counterBits)
+	if err != nil {
+		return nil, err
+	}
+	s := &CountMinSketch[T]{
+		width:     width,
+		depth:     depth,
+		update:    update,
+		estimator: estimator,
+		r:         r,
+		h:         h,
+	}
+	return s, nil
+}
+
+func (s *CountMinSketch[T]) Width() uint      { return s.width }
+func (s *CountMinSketch[T]) Depth() uint      { return s.depth }
+func (s *CountMinSketch[T]) TotalCount() uint { return s.total }
+func (s *CountMinSketch[T]) HashAttr() string { return s.h.String() }
+func (s *CountMinSketch[T]) ByteSize() uint   { return register.GetByteSize(s.r) }
+
+// eps = e / w
+func (s *CountMinSketch[T]) Epsilon() float64 {
+	return math.E / float64(s.width)
+}
+
+// 1 - delta = 1 - e^-d
+func (s *CountMinSketch[T]) Confidence() float64 {
+	return 1 - math.Exp(-float64(s.depth))
+}
+
+// max overestimation eps * N, holds with Confidence()
+func (s *CountMinSketch[T]) ErrorBound() float64 {
+	return s.Epsilon() * float64(s.total)
+}
+
+// 1 counter offset per row
+func (s *CountMinSketch[T]) offsets(item []byte) ([]uint, error) {
+	hashes, err := s.h.GenerateHash(item, 0, s.width, s.depth)
+	if err != nil {
+		return nil, err
+	}
+	offsets := make([]uint, s.depth)
+	for row, hash := range hashes {
+		offsets[row] = uint(row)*s.width + uint(hash%T(s.width))
+	}
+	return offsets, nil
+}
+
+// counters saturate at register max value instead of overflowing
+func (s *CountMinSketch[T]) saturatingAdd(value, n uint) uint {
+	if n > s.r.MaxValue()-value {
+		return s.r.MaxValue()
+	}
+	return value + n
+}
+
+func (s *CountMinSketch[T]) Add(item []byte, n uint) error {
+	offsets, err := s.offsets(item)
+	if err != nil {
+		return err
+	}
+	if s.update == ConservativeUpdate {
+		return s.addConservative(offsets, n)
+	}
+	for _, offset := range offsets {
+		v, err := s.r.Read(offset)
+		if err != nil {
+			return err
+		}
+		if _, err = s.r.Write(offset, s.saturatingAdd(v, n)); err != nil {
+			return err
+		}
+	}
+	s.total += n
+	return nil
+}
+
+func (s *CountMinSketch[T]) addConservative(offsets []uint, n uint) error {
+	target := s.saturatingAdd(s.minCounter(offsets), n)
+	for _, offset := range offsets {
+		v, err := s.r.Read(offset)
+		if err != nil {
+			return err
+		}
+		if v < target {
+			if _, err = s.r.Write(offset, target); err != nil {
+				return err
+			}
+		}
+	}
+	s.total += n
+	return nil
+}
+
+func (s *CountMinSketch[T]) minCounter(offsets []uint) uint {
+	estimate := s.r.MaxValue()
+	for _, offset := range offsets {
+		v, _ := s.r.Read(offset)
+		estimate = min(estimate, v)
+	}
+	return estimate
+}
+
+func (s *CountMinSketch[T]) Estimate(item []byte) uint {
+	offsets, err := s.offsets(item)
+	if err != nil {
+		return 0
+	}
+	estimate := s.minCounter(offsets)
+	if s.estimator == CountMeanMinEstimator {
+		return s.countMeanMin(offsets, estimate)
+	}
+	return estimate
+}
+
+func (s *CountMinSketch[T]) countMeanMin(offsets []uint, minEstimate uint) uint {
+	if s.width < 2 || minEstimate == s.r.MaxValue() {
+		// every row is saturated, nothing better than the minimum
+		return minEstimate
+	}
+	rowEstimates := make([]float64, 0, len(offsets))
+	for _, offset := range offsets {
+		v, _ := s.r.Read(offset)
+		if v == s.r.MaxValue() {
+			// saturated counter lost its true value, so its noise is unknown
+			continue
+		}
+		noise := (float64(s.total) - float64(v)) / float64(s.width-1)
+		rowEstimates = append(rowEstimates, float64(v)-noise)
+	}
+
+	sort.Float64s(rowEstimates)
+	mid := len(rowEstimates) / 2
+	median := rowEstimates[mid]
+	if len(rowEstimates)%2 == 0 {
+		median = (rowEstimates[mid-1] + rowEstimates[mid]) / 2
+	}
+	if median <= 0 {
+		return 0
+	}
+	return min(minEstimate, uint(math.Round(median)))
+}
+
+// counter-wise sum, both sketches must share dimensions, counter width and hash function
+func (s *CountMinSketch[T]) Merge(other *CountMinSketch[T]) error {
+	if s.width != other.width || s.depth != other.depth ||
+		s.r.BitWidth() != other.r.BitWidth() ||
+		!s.h.Compatible(other.h) {
+		return fmt.Errorf(
+			MismatchedSketchMsg,
+			s.width, s.depth, s.r.BitWidth(), s.h.String(),
+			other.width, other.depth, other.r.BitWidth(), other.h.String(),
+		)
+	}
+	for offset := uint(0); offset < s.width*s.depth; offset++ {
+		v, _ := s.r.Read(offset)
+		w, _ := other.r.Read(offset)
+		if _, err := s.r.Write(offset, s.saturatingAdd(v, w)); err != nil {
+			return err
+		}
+	}
+	s.total += other.total
+	return nil
+}
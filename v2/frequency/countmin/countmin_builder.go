@@ -0,0 +1,71 @@
+package countmin
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type CountMinSketchBuilder[T hasher.HashOutType] struct {
+	width       uint
+	depth       uint
+	counterBits uint
+	update      UpdateMode
+	estimator   Estimator
+	h           hasher.HashGenerator[T]
+	hashErr     error
+}
+
+func NewCountMinSketchBuilder[T hasher.HashOutType]() *CountMinSketchBuilder[T] {
+	defaultWidth, defaultDepth := CountMinEstimateParams(0.001, 0.01)
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &CountMinSketchBuilder[T]{
+		width:       defaultWidth,
+		depth:       defaultDepth,
+		counterBits: 32,
+		update:      StandardUpdate,
+		estimator:   MinEstimator,
+		h:           *defaultHasher,
+	}
+}
+
+func (b *CountMinSketchBuilder[T]) SetWidth(width uint) *CountMinSketchBuilder[T] {
+	b.width = width
+	return b
+}
+
+func (b *CountMinSketchBuilder[T]) SetDepth(depth uint) *CountMinSketchBuilder[T] {
+	b.depth = depth
+	return b
+}
+
+// counters saturate at 2^bits - 1
+func (b *CountMinSketchBuilder[T]) SetCounterBits(counterBits uint) *CountMinSketchBuilder[T] {
+	b.counterBits = counterBits
+	return b
+}
+
+func (b *CountMinSketchBuilder[T]) SetUpdateMode(update UpdateMode) *CountMinSketchBuilder[T] {
+	b.update = update
+	return b
+}
+
+func (b *CountMinSketchBuilder[T]) SetEstimator(estimator Estimator) *CountMinSketchBuilder[T] {
+	b.estimator = estimator
+	return b
+}
+
+func (b *CountMinSketchBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *CountMinSketchBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *CountMinSketchBuilder[T]) Build() (*CountMinSketch[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newCountMinSketch(b.width, b.depth, b.counterBits, b.update, b.estimator, b.h)
+}
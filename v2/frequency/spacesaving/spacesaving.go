@@ -0,0 +1,295 @@
+package spacesaving
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// Space-Saving (Metwally et al., 2005) on a Stream-Summary
+// m monitored counters, an unmonitored item replaces the minimum counter and inherits its count as error
+// every count overestimates by at most min count <= N / m, items with frequency > N / m are always monitored
+//
+// counters with equal count share a bucket, buckets form a list sorted by count
+// so +1 updates and finding the minimum are O(1)
+type SpaceSaving[T hasher.HashOutType] struct {
+	capacity uint
+	keyBytes uint
+	total    uint // N, sum of all added counts
+	counters map[T]*counter[T]
+	min      *bucket[T] // head of bucket list
+	h        hasher.HashGenerator[T]
+}
+
+type bucket[T hasher.HashOutType] struct {
+	count      uint
+	head       *counter[T]
+	prev, next *bucket[T]
+}
+
+type counter[T hasher.HashOutType] struct {
+	fp         T
+	key        []byte
+	err        uint
+	bucket     *bucket[T]
+	prev, next *counter[T]
+}
+
+// true count is in [Count - Error, Count]
+type Item[T hasher.HashOutType] struct {
+	Fingerprint T
+	Key         []byte // up to key bytes of the 1st key seen with this fingerprint
+	Count       uint
+	Error       uint
+	Guaranteed  bool // Count - Error >= count of the next monitored item, so it surely belongs in the top k
+}
+
+const (
+	InvalidCapacityMsg   = "invalid capacity (%v <= 0)"
+	MismatchedSummaryMsg = "mismatched summaries: (capacity = %v, hash = %v) != (capacity = %v, hash = %v)"
+)
+
+// counters needed so that every count is within eps * N
+func SpaceSavingEstimateParams(epsilon float64) (capacity uint) {
+	return uint(math.Ceil(1 / epsilon))
+}
+
+func newSpaceSaving[T hasher.HashOutType](capacity, keyBytes uint, h hasher.HashGenerator[T]) (*SpaceSaving[T], error) {
+	if capacity == 0 {
+		return nil, fmt.Errorf(InvalidCapacityMsg, capacity)
+	}
+	s := &SpaceSaving[T]{
+		capacity: capacity,
+		keyBytes: keyBytes,
+		counters: make(map[T]*counter[T], capacity),
+		h:        h,
+	}
+	return s, nil
+}
+
+func (s *SpaceSaving[T]) Cap() uint        { return s.capacity }
+func (s *SpaceSaving[T]) Len() uint        { return uint(len(s.counters)) }
+func (s *SpaceSaving[T]) TotalCount() uint { return s.total }
+func (s *SpaceSaving[T]) HashAttr() string { return s.h.String() }
+
+// max overestimation of any count, 0 until all counters are in use
+func (s *SpaceSaving[T]) ErrorBound() uint {
+	if uint(len(s.counters)) < s.capacity || s.min == nil {
+		return 0
+	}
+	return s.min.count
+}
+
+func (s *SpaceSaving[T]) fingerprint(item []byte) (T, error) {
+	hashes, err := s.h.GenerateHash(item, 0, math.MaxUint, 1)
+	if err != nil {
+		return 0, err
+	}
+	return hashes[0], nil
+}
+
+func (s *SpaceSaving[T]) label(item []byte) []byte {
+	n := min(uint(len(item)), s.keyBytes)
+	key := make([]byte, n)
+	copy(key, item[:n])
+	return key
+}
+
+func (b *bucket[T]) attach(c *counter[T]) {
+	c.bucket = b
+	c.prev = nil
+	c.next = b.head
+	if b.head != nil {
+		b.head.prev = c
+	}
+	b.head = c
+}
+
+func (b *bucket[T]) detach(c *counter[T]) {
+	if c.prev != nil {
+		c.prev.next = c.next
+	} else {
+		b.head = c.next
+	}
+	if c.next != nil {
+		c.next.prev = c.prev
+	}
+	c.prev, c.next, c.bucket = nil, nil, nil
+}
+
+func (s *SpaceSaving[T]) unlink(b *bucket[T]) {
+	if b.prev != nil {
+		b.prev.next = b.next
+	} else {
+		s.min = b.next
+	}
+	if b.next != nil {
+		b.next.prev = b.prev
+	}
+}
+
+// attach counter to bucket of count, searching forward from bucket after
+// after = nil searches from the minimum
+func (s *SpaceSaving[T]) place(c *counter[T], count uint, after *bucket[T]) {
+	prev, next := after, s.min
+	if after != nil {
+		next = after.next
+	}
+	for next != nil && next.count < count {
+		prev, next = next, next.next
+	}
+	if next != nil && next.count == count {
+		next.attach(c)
+		return
+	}
+
+	b := &bucket[T]{count: count, prev: prev, next: next}
+	if prev != nil {
+		prev.next = b
+	} else {
+		s.min = b
+	}
+	if next != nil {
+		next.prev = b
+	}
+	b.attach(c)
+}
+
+func (s *SpaceSaving[T]) increment(c *counter[T], n uint) {
+	old := c.bucket
+	count := old.count + n
+	old.detach(c)
+	// search starts from old bucket, +1 updates land in old.next or a new bucket right after old
+	s.place(c, count, old)
+	if old.head == nil {
+		s.unlink(old)
+	}
+}
+
+func (s *SpaceSaving[T]) Add(item []byte, n uint) error {
+	if n == 0 {
+		return nil
+	}
+	fp, err := s.fingerprint(item)
+	if err != nil {
+		return err
+	}
+	s.insert(fp, item, n, 0)
+	return nil
+}
+
+func (s *SpaceSaving[T]) insert(fp T, item []byte, n uint, err uint) {
+	s.total += n
+	if c, ok := s.counters[fp]; ok {
+		c.err += err
+		s.increment(c, n)
+		return
+	}
+
+	if uint(len(s.counters)) < s.capacity {
+		c := &counter[T]{fp: fp, key: s.label(item), err: err}
+		s.counters[fp] = c
+		s.place(c, n, nil)
+		return
+	}
+
+	// evict a minimum counter, newcomer inherits its count as error
+	c := s.min.head
+	delete(s.counters, c.fp)
+	c.fp, c.key, c.err = fp, s.label(item), s.min.count+err
+	s.counters[fp] = c
+	s.increment(c, n)
+}
+
+// upper bound of item count, 0 if unmonitored
+func (s *SpaceSaving[T]) Estimate(item []byte) (count, err uint) {
+	fp, hashErr := s.fingerprint(item)
+	if hashErr != nil {
+		return 0, 0
+	}
+	if c, ok := s.counters[fp]; ok {
+		return c.bucket.count, c.err
+	}
+	return 0, 0
+}
+
+// all monitored items by descending count
+func (s *SpaceSaving[T]) items() []Item[T] {
+	items := make([]Item[T], 0, len(s.counters))
+	last := s.min
+	for last != nil && last.next != nil {
+		last = last.next
+	}
+	for b := last; b != nil; b = b.prev {
+		for c := b.head; c != nil; c = c.next {
+			items = append(items, Item[T]{Fingerprint: c.fp, Key: c.key, Count: b.count, Error: c.err})
+		}
+	}
+	return items
+}
+
+// k most frequent monitored items, by descending count
+func (s *SpaceSaving[T]) TopK(k uint) []Item[T] {
+	items := s.items()
+	k = min(k, uint(len(items)))
+	next := uint(0)
+	if k < uint(len(items)) {
+		next = items[k].Count
+	} else if uint(len(s.counters)) == s.capacity {
+		// unmonitored items may have up to min count
+		next = s.min.count
+	}
+	for i := uint(0); i < k; i++ {
+		items[i].Guaranteed = items[i].Count-items[i].Error >= next
+	}
+	return items[:k]
+}
+
+// mergeable summaries (Agarwal et al., 2012): an item missing from a full summary
+// may have up to its min count there, so it is added as count and error
+func (s *SpaceSaving[T]) Merge(other *SpaceSaving[T]) error {
+	if s.capacity != other.capacity || !s.h.Compatible(other.h) {
+		return fmt.Errorf(MismatchedSummaryMsg, s.capacity, s.h.String(), other.capacity, other.h.String())
+	}
+
+	selfMin, otherMin := s.ErrorBound(), other.ErrorBound()
+	merged := map[T]*Item[T]{}
+	for _, item := range s.items() {
+		item.Count += otherMin
+		item.Error += otherMin
+		merged[item.Fingerprint] = &item
+	}
+	for _, item := range other.items() {
+		if m, ok := merged[item.Fingerprint]; ok {
+			// drop the missing-item allowance, it's monitored in both
+			m.Count += item.Count - otherMin
+			m.Error += item.Error - otherMin
+			continue
+		}
+		item.Count += selfMin
+		item.Error += selfMin
+		merged[item.Fingerprint] = &item
+	}
+
+	items := make([]*Item[T], 0, len(merged))
+	for _, item := range merged {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Count > items[j].Count })
+	if uint(len(items)) > s.capacity {
+		items = items[:s.capacity]
+	}
+
+	total := s.total + other.total
+	s.counters = make(map[T]*counter[T], s.capacity)
+	s.min = nil
+	for i := len(items) - 1; i >= 0; i-- {
+		c := &counter[T]{fp: items[i].Fingerprint, key: items[i].Key, err: items[i].Error}
+		s.counters[c.fp] = c
+		s.place(c, items[i].Count, nil)
+	}
+	s.total = total
+	return nil
+}
@@ -0,0 +1,50 @@
+package spacesaving
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type SpaceSavingBuilder[T hasher.HashOutType] struct {
+	capacity uint
+	keyBytes uint
+	h        hasher.HashGenerator[T]
+	hashErr  error
+}
+
+func NewSpaceSavingBuilder[T hasher.HashOutType]() *SpaceSavingBuilder[T] {
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &SpaceSavingBuilder[T]{
+		capacity: SpaceSavingEstimateParams(0.001),
+		keyBytes: 32,
+		h:        *defaultHasher,
+	}
+}
+
+// number of monitored counters
+func (b *SpaceSavingBuilder[T]) SetCap(capacity uint) *SpaceSavingBuilder[T] {
+	b.capacity = capacity
+	return b
+}
+
+// bytes of key kept next to its fingerprint for reporting, 0 keeps fingerprints only
+func (b *SpaceSavingBuilder[T]) SetKeyBytes(keyBytes uint) *SpaceSavingBuilder[T] {
+	b.keyBytes = keyBytes
+	return b
+}
+
+func (b *SpaceSavingBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *SpaceSavingBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *SpaceSavingBuilder[T]) Build() (*SpaceSaving[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newSpaceSaving(b.capacity, b.keyBytes, b.h)
+}
@@ -2,6 +2,7 @@ package bloomfilter
 
 import (
 	"math"
+	"sync"
 
 	"github.com/nnurry/probabilistics/v2/utilities/hasher"
 	"github.com/nnurry/probabilistics/v2/utilities/register"
@@ -10,10 +11,12 @@ import (
 const SquaredLn2 = math.Ln2 * math.Ln2
 
 type ClassicBF[T hasher.HashOutType] struct {
-	cap uint
-	k   uint
-	r   *register.BitRegister
-	h   hasher.HashGenerator[T]
+	cap  uint
+	k    uint
+	r    *register.BitRegister
+	h    hasher.HashGenerator[T]
+	idx  hasher.Indexer[T]
+	bufs sync.Pool // *[]T scratch for hashes, so Add / Contains neither allocate nor share a buffer
 }
 
 func estCap(fpr float64, elems float64) float64 {
@@ -34,19 +37,37 @@ func ClassicBFEstimateParams(fpr float64, elems uint) (m, k uint) {
 func (f *ClassicBF[T]) Cap() uint        { return f.cap }
 func (f *ClassicBF[T]) HashAttr() string { return f.h.String() }
 
+// hashes of data in a pooled buffer, hand it back with f.bufs.Put
+// on error the buffer is empty
+func (f *ClassicBF[T]) hashes(data []byte) *[]T {
+	buf, _ := f.bufs.Get().(*[]T)
+	if buf == nil {
+		buf = new([]T)
+	}
+	hashes, err := f.h.GenerateHashInto(*buf, data, 0, f.idx.HashCeil(), f.k)
+	if err != nil {
+		*buf = (*buf)[:0]
+		return buf
+	}
+	*buf = hashes
+	return buf
+}
+
 func (f *ClassicBF[T]) Add(data []byte) *ClassicBF[T] {
-	hashes, _ := f.h.GenerateHash(data, 0, f.cap, f.k)
-	for _, hash := range hashes {
-		rIdx := uint(hash % T(f.cap))
+	hashes := f.hashes(data)
+	defer f.bufs.Put(hashes)
+	for _, hash := range *hashes {
+		rIdx := f.idx.Index(hash)
 		f.r.Write(rIdx, 1)
 	}
 	return f
 }
 
 func (f *ClassicBF[T]) Contains(data []byte) bool {
-	hashes, _ := f.h.GenerateHash(data, 0, f.cap, f.k)
-	for _, hash := range hashes {
-		rIdx := uint(hash % T(f.cap))
+	hashes := f.hashes(data)
+	defer f.bufs.Put(hashes)
+	for _, hash := range *hashes {
+		rIdx := f.idx.Index(hash)
 		v, err := f.r.Read(rIdx)
 		if err != nil || v == 0 {
 			return false
@@ -1,25 +1,32 @@
 package bloomfilter
 
 import (
+	"fmt"
+
 	"github.com/nnurry/probabilistics/v2/utilities/hasher"
 	"github.com/nnurry/probabilistics/v2/utilities/register"
 )
 
+const (
+	SmallRegisterMsg = "register capacity %v < %v slots"
+)
+
 type ClassicBFBuilder[T hasher.HashOutType] struct {
-	cap uint
-	k   uint
-	r   *register.BitRegister
-	h   hasher.HashGenerator[T]
+	cap     uint
+	k       uint
+	r       *register.BitRegister // made by Build when unset
+	h       hasher.HashGenerator[T]
+	hashErr error // of the last SetHashGenerator, returned by Build
+	// multiply-shift by default
+	indexMode hasher.IndexMode
 }
 
 func NewClassicBFBuilder[T hasher.HashOutType]() *ClassicBFBuilder[T] {
 	defaultCap, defaultK := ClassicBFEstimateParams(0.01, 10000)
 	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
-	defaultRegister, _ := register.NewRegister(defaultCap, 1)
 	return &ClassicBFBuilder[T]{
 		cap: defaultCap,
 		k:   defaultK,
-		r:   defaultRegister.(*register.BitRegister),
 		h:   *defaultHasher,
 	}
 }
@@ -34,13 +41,15 @@ func (b *ClassicBFBuilder[T]) SetHashNum(k uint) *ClassicBFBuilder[T] {
 	return b
 }
 
+// r must hold every slot, Cap of the built filter (rounded up with hasher.MaskIndex)
 func (b *ClassicBFBuilder[T]) SetRegister(r *register.BitRegister) *ClassicBFBuilder[T] {
 	b.r = r
 	return b
 }
 
-func (b *ClassicBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string) *ClassicBFBuilder[T] {
-	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod)
+func (b *ClassicBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *ClassicBFBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
 	if err != nil {
 		return b
 	}
@@ -48,12 +57,44 @@ func (b *ClassicBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit ui
 	return b
 }
 
+// hasher.MaskIndex rounds the capacity up to a power of 2
+// it keeps the low bits, so double hashing schemes cycle: "kirsch-mitzenmacher" collides within a key ~1.7x
+// as often as independent hashes for k = 7 and "kirsch-mitzenmacher-legacy" ~2x on wide families (see hasher/quality)
+func (b *ClassicBFBuilder[T]) SetIndexMode(mode hasher.IndexMode) *ClassicBFBuilder[T] {
+	b.indexMode = mode
+	return b
+}
+
+// Build without the error, nil when the filter can't be built
 func (b *ClassicBFBuilder[T]) Build() *ClassicBF[T] {
+	bf, _ := b.TryBuild()
+	return bf
+}
+
+func (b *ClassicBFBuilder[T]) TryBuild() (*ClassicBF[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	idx, err := hasher.NewIndexer[T](b.cap, b.indexMode)
+	if err != nil {
+		return nil, err
+	}
+	r := b.r
+	if r == nil {
+		newR, err := register.NewRegister(idx.Size(), 1)
+		if err != nil {
+			return nil, err
+		}
+		r = newR.(*register.BitRegister)
+	} else if r.Capacity() < idx.Size() {
+		return nil, fmt.Errorf(SmallRegisterMsg, r.Capacity(), idx.Size())
+	}
 	bf := &ClassicBF[T]{
-		cap: b.cap,
+		cap: idx.Size(),
 		k:   b.k,
-		r:   b.r,
+		r:   r,
 		h:   b.h,
+		idx: idx,
 	}
-	return bf
+	return bf, nil
 }
@@ -1,6 +1,8 @@
 package bloomfilter
 
 import (
+	"sync"
+
 	"github.com/nnurry/probabilistics/v2/utilities/hasher"
 	"github.com/nnurry/probabilistics/v2/utilities/register"
 )
@@ -11,15 +13,34 @@ type CountingBF[T hasher.HashOutType] struct {
 	bitR   *register.BitRegister
 	countR register.Register
 	h      hasher.HashGenerator[T]
+	idx    hasher.Indexer[T]
+	bufs   sync.Pool // *[]T scratch for hashes, so Add / Contains neither allocate nor share a buffer
 }
 
 func (f *CountingBF[T]) Cap() uint        { return f.cap }
 func (f *CountingBF[T]) HashAttr() string { return f.h.String() }
 
+// hashes of data in a pooled buffer, hand it back with f.bufs.Put
+// on error the buffer is empty
+func (f *CountingBF[T]) hashes(data []byte) *[]T {
+	buf, _ := f.bufs.Get().(*[]T)
+	if buf == nil {
+		buf = new([]T)
+	}
+	hashes, err := f.h.GenerateHashInto(*buf, data, 0, f.idx.HashCeil(), f.k)
+	if err != nil {
+		*buf = (*buf)[:0]
+		return buf
+	}
+	*buf = hashes
+	return buf
+}
+
 func (f *CountingBF[T]) Add(data []byte) *CountingBF[T] {
-	hashes, _ := f.h.GenerateHash(data, 0, f.cap, f.k)
-	for _, hash := range hashes {
-		rIdx := uint(hash % T(f.cap))
+	hashes := f.hashes(data)
+	defer f.bufs.Put(hashes)
+	for _, hash := range *hashes {
+		rIdx := f.idx.Index(hash)
 		f.bitR.Write(rIdx, 1)
 		f.countR.Increment(rIdx)
 	}
@@ -27,9 +48,10 @@ func (f *CountingBF[T]) Add(data []byte) *CountingBF[T] {
 }
 
 func (f *CountingBF[T]) Remove(data []byte) *CountingBF[T] {
-	hashes, _ := f.h.GenerateHash(data, 0, f.cap, f.k)
-	for _, hash := range hashes {
-		rIdx := uint(hash % T(f.cap))
+	hashes := f.hashes(data)
+	defer f.bufs.Put(hashes)
+	for _, hash := range *hashes {
+		rIdx := f.idx.Index(hash)
 		_, after, _ := f.countR.Decrement(rIdx)
 		if after == 0 {
 			f.bitR.Write(rIdx, 0)
@@ -39,9 +61,10 @@ func (f *CountingBF[T]) Remove(data []byte) *CountingBF[T] {
 }
 
 func (f *CountingBF[T]) Contains(data []byte) bool {
-	hashes, _ := f.h.GenerateHash(data, 0, f.cap, f.k)
-	for _, hash := range hashes {
-		rIdx := uint(hash % T(f.cap))
+	hashes := f.hashes(data)
+	defer f.bufs.Put(hashes)
+	for _, hash := range *hashes {
+		rIdx := f.idx.Index(hash)
 		v, err := f.bitR.Read(rIdx)
 		if err != nil || v == 0 {
 			return false
@@ -1,16 +1,21 @@
 package bloomfilter
 
 import (
+	"fmt"
+
 	"github.com/nnurry/probabilistics/v2/utilities/hasher"
 	"github.com/nnurry/probabilistics/v2/utilities/register"
 )
 
 type CountingBFBuilder[T hasher.HashOutType] struct {
-	cap    uint
-	k      uint
-	bitR   *register.BitRegister
-	countR register.Register
-	h      hasher.HashGenerator[T]
+	cap     uint
+	k       uint
+	bitR    *register.BitRegister // made by Build when unset
+	countR  register.Register     // 4-bit counters made by Build when unset
+	h       hasher.HashGenerator[T]
+	hashErr error
+	// multiply-shift by default
+	indexMode hasher.IndexMode
 }
 
 func NewCountingBFBuilder[T hasher.HashOutType]() *CountingBFBuilder[T] {
@@ -18,14 +23,10 @@ func NewCountingBFBuilder[T hasher.HashOutType]() *CountingBFBuilder[T] {
 	// so let's use optimization function of classic BF
 	defaultCap, defaultK := ClassicBFEstimateParams(0.01, 10000)
 	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
-	defaultBitRegister, _ := register.NewRegister(defaultCap, 1)
-	defaultCountRegister, _ := register.NewRegister(defaultCap, 4)
 	return &CountingBFBuilder[T]{
-		cap:    defaultCap,
-		k:      defaultK,
-		bitR:   defaultBitRegister.(*register.BitRegister),
-		countR: defaultCountRegister,
-		h:      *defaultHasher,
+		cap: defaultCap,
+		k:   defaultK,
+		h:   *defaultHasher,
 	}
 }
 
@@ -39,6 +40,7 @@ func (b *CountingBFBuilder[T]) SetHashNum(k uint) *CountingBFBuilder[T] {
 	return b
 }
 
+// registers must hold every slot, Cap of the built filter (rounded up with hasher.MaskIndex)
 func (b *CountingBFBuilder[T]) SetBitRegister(r *register.BitRegister) *CountingBFBuilder[T] {
 	b.bitR = r
 	return b
@@ -49,8 +51,9 @@ func (b *CountingBFBuilder[T]) SetCountRegister(r register.Register) *CountingBF
 	return b
 }
 
-func (b *CountingBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string) *CountingBFBuilder[T] {
-	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod)
+func (b *CountingBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *CountingBFBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
 	if err != nil {
 		return b
 	}
@@ -58,13 +61,52 @@ func (b *CountingBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit u
 	return b
 }
 
+// hasher.MaskIndex rounds the capacity up to a power of 2
+// it keeps the low bits, so double hashing schemes cycle: "kirsch-mitzenmacher" collides within a key ~1.7x
+// as often as independent hashes for k = 7 and "kirsch-mitzenmacher-legacy" ~2x on wide families (see hasher/quality)
+func (b *CountingBFBuilder[T]) SetIndexMode(mode hasher.IndexMode) *CountingBFBuilder[T] {
+	b.indexMode = mode
+	return b
+}
+
+// Build without the error, nil when the filter can't be built
 func (b *CountingBFBuilder[T]) Build() *CountingBF[T] {
+	bf, _ := b.TryBuild()
+	return bf
+}
+
+func (b *CountingBFBuilder[T]) TryBuild() (*CountingBF[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	idx, err := hasher.NewIndexer[T](b.cap, b.indexMode)
+	if err != nil {
+		return nil, err
+	}
+	bitR, countR := b.bitR, b.countR
+	if bitR == nil {
+		newR, err := register.NewRegister(idx.Size(), 1)
+		if err != nil {
+			return nil, err
+		}
+		bitR = newR.(*register.BitRegister)
+	} else if bitR.Capacity() < idx.Size() {
+		return nil, fmt.Errorf(SmallRegisterMsg, bitR.Capacity(), idx.Size())
+	}
+	if countR == nil {
+		if countR, err = register.NewRegister(idx.Size(), 4); err != nil {
+			return nil, err
+		}
+	} else if countR.Capacity() < idx.Size() {
+		return nil, fmt.Errorf(SmallRegisterMsg, countR.Capacity(), idx.Size())
+	}
 	bf := &CountingBF[T]{
-		cap:    b.cap,
+		cap:    idx.Size(),
 		k:      b.k,
-		bitR:   b.bitR,
-		countR: b.countR,
+		bitR:   bitR,
+		countR: countR,
 		h:      b.h,
+		idx:    idx,
 	}
-	return bf
+	return bf, nil
 }
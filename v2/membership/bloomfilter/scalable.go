@@ -0,0 +1,127 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+const (
+	InvalidScalableParamsMsg = "invalid scalable bloom filter params: (fpr = %v, elems = %v, growth = %v, tightening = %v)"
+)
+
+// Scalable Bloom filter (Almeida et al., 2007)
+// chain of classic BFs, stage i is planned for n0 * s^i elements at fpr p0 * r^i
+// so the compound fpr stays under p0 / (1 - r) = target fpr
+type ScalableBF[T hasher.HashOutType] struct {
+	fpr        float64
+	elems      uint // planned elements of the 1st stage
+	growth     float64
+	tightening float64
+	stages     []*ClassicBF[T]
+	stageElems uint // planned elements of the last stage
+	stageCount uint // added elements of the last stage
+	count      uint
+	h          hasher.HashGenerator[T]
+	indexMode  hasher.IndexMode
+}
+
+// fpr and tightening in (0, 1), growth >= 1, elems > 0
+func validScalableBFParams(fpr float64, elems uint, growth float64, tightening float64) error {
+	if !(fpr > 0 && fpr < 1) || elems == 0 || !(growth >= 1) || math.IsInf(growth, 1) || !(tightening > 0 && tightening < 1) {
+		return fmt.Errorf(InvalidScalableParamsMsg, fpr, elems, growth, tightening)
+	}
+	return nil
+}
+
+func ScalableBFStageParams(fpr float64, elems uint, growth float64, tightening float64, stage uint) (stageFpr float64, stageElems uint) {
+	// p0 = P * (1 - r) -> sum(p0 * r^i) = P
+	stageFpr = fpr * (1 - tightening) * math.Pow(tightening, float64(stage))
+	stageElems = uint(math.Ceil(float64(elems) * math.Pow(growth, float64(stage))))
+	return stageFpr, stageElems
+}
+
+func (f *ScalableBF[T]) Stages() uint     { return uint(len(f.stages)) }
+func (f *ScalableBF[T]) Count() uint      { return f.count }
+func (f *ScalableBF[T]) HashAttr() string { return f.h.String() }
+
+func (f *ScalableBF[T]) Cap() uint {
+	capacity := uint(0)
+	for _, stage := range f.stages {
+		capacity += stage.Cap()
+	}
+	return capacity
+}
+
+func (f *ScalableBF[T]) ByteSize() uint {
+	size := uint(0)
+	for _, stage := range f.stages {
+		size += register.GetByteSize(stage.r)
+	}
+	return size
+}
+
+// compound fpr of current stages: 1 - prod(1 - p_i)
+func (f *ScalableBF[T]) EstimateFpr() float64 {
+	fpr := 1.0
+	for i := range f.stages {
+		stageFpr, _ := ScalableBFStageParams(f.fpr, f.elems, f.growth, f.tightening, uint(i))
+		fpr *= 1 - stageFpr
+	}
+	return 1 - fpr
+}
+
+func (f *ScalableBF[T]) addStage() error {
+	stage := uint(len(f.stages))
+	stageFpr, stageElems := ScalableBFStageParams(f.fpr, f.elems, f.growth, f.tightening, stage)
+
+	m, k := ClassicBFEstimateParams(stageFpr, stageElems)
+	if k == 0 {
+		k = 1
+	}
+	idx, err := hasher.NewIndexer[T](m, f.indexMode)
+	if err != nil {
+		return err
+	}
+	r, err := register.NewRegister(idx.Size(), 1)
+	if err != nil {
+		return err
+	}
+
+	bf := &ClassicBF[T]{
+		cap: idx.Size(),
+		k:   k,
+		r:   r.(*register.BitRegister),
+		h:   f.h,
+		idx: idx,
+	}
+	f.stages = append(f.stages, bf)
+	f.stageElems = stageElems
+	f.stageCount = 0
+	return nil
+}
+
+func (f *ScalableBF[T]) Add(data []byte) *ScalableBF[T] {
+	if f.stageCount >= f.stageElems {
+		// last stage reached its planned elements -> grow
+		// no room for a new stage (index or register too large) -> overfill the last one,
+		// fpr rises past the target but added elements are never lost
+		_ = f.addStage()
+	}
+	f.stages[len(f.stages)-1].Add(data)
+	f.stageCount++
+	f.count++
+	return f
+}
+
+func (f *ScalableBF[T]) Contains(data []byte) bool {
+	// newest stage holds the most elements, check it first
+	for i := len(f.stages) - 1; i >= 0; i-- {
+		if f.stages[i].Contains(data) {
+			return true
+		}
+	}
+	return false
+}
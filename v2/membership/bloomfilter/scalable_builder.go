@@ -0,0 +1,84 @@
+package bloomfilter
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type ScalableBFBuilder[T hasher.HashOutType] struct {
+	fpr        float64
+	elems      uint
+	growth     float64
+	tightening float64
+	h          hasher.HashGenerator[T]
+	hashErr    error
+	indexMode  hasher.IndexMode
+}
+
+func NewScalableBFBuilder[T hasher.HashOutType]() *ScalableBFBuilder[T] {
+	// growth = 2 and tightening = 0.85 are suggested for slow-growing sets
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &ScalableBFBuilder[T]{
+		fpr:        0.01,
+		elems:      10000,
+		growth:     2,
+		tightening: 0.85,
+		h:          *defaultHasher,
+	}
+}
+
+func (b *ScalableBFBuilder[T]) SetFpr(fpr float64) *ScalableBFBuilder[T] {
+	b.fpr = fpr
+	return b
+}
+
+func (b *ScalableBFBuilder[T]) SetElems(elems uint) *ScalableBFBuilder[T] {
+	b.elems = elems
+	return b
+}
+
+func (b *ScalableBFBuilder[T]) SetGrowth(growth float64) *ScalableBFBuilder[T] {
+	b.growth = growth
+	return b
+}
+
+func (b *ScalableBFBuilder[T]) SetTightening(tightening float64) *ScalableBFBuilder[T] {
+	b.tightening = tightening
+	return b
+}
+
+func (b *ScalableBFBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *ScalableBFBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+// index mode of every stage, hasher.MaskIndex rounds their capacities up to powers of 2
+func (b *ScalableBFBuilder[T]) SetIndexMode(mode hasher.IndexMode) *ScalableBFBuilder[T] {
+	b.indexMode = mode
+	return b
+}
+
+func (b *ScalableBFBuilder[T]) Build() (*ScalableBF[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	if err := validScalableBFParams(b.fpr, b.elems, b.growth, b.tightening); err != nil {
+		return nil, err
+	}
+	bf := &ScalableBF[T]{
+		fpr:        b.fpr,
+		elems:      b.elems,
+		growth:     b.growth,
+		tightening: b.tightening,
+		h:          b.h,
+		indexMode:  b.indexMode,
+	}
+	if err := bf.addStage(); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
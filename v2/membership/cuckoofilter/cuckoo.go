@@ -0,0 +1,166 @@
+package cuckoofilter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// Cuckoo filter (Fan et al., 2014)
+// buckets of b fingerprints, an item lives in bucket i1 = h(x) or i2 = i1 ^ h(fp)
+// partial-key cuckoo hashing: i2 can be derived from i1 and fp alone so we can relocate w/o the item
+type CuckooFilter[T hasher.HashOutType] struct {
+	buckets    uint // power of 2 so that i1 ^ h(fp) stays in range
+	bucketSize uint
+	fpBits     uint
+	maxKicks   uint
+	count      uint
+	r          register.Register // buckets * bucketSize fingerprint cells, 0 = empty
+	h          hasher.HashGenerator[T]
+	rng        *rand.Rand
+}
+
+const (
+	FilterFullMsg = "filter is full (%v kicks exhausted, count = %v)"
+)
+
+// used by reference implementation (murmur2 multiplier) to spread fp over bucket index
+const fingerprintMixer = 0x5bd1e995
+
+type kick struct {
+	offset uint
+	value  uint
+}
+
+func nextPowerOf2(x uint) uint {
+	p := uint(1)
+	for p < x {
+		p <<= 1
+	}
+	return p
+}
+
+// buckets and fingerprint bits for given fpr and elements
+// fpr ~ 2b / 2^f -> f = ceil(log2(2b / fpr)), load factor ~ 95% at b = 4
+func CuckooFilterEstimateParams(fpr float64, elems uint, bucketSize uint) (buckets, fpBits uint) {
+	loadFactor := 0.95
+	if bucketSize < 4 {
+		loadFactor = 0.84
+	}
+	buckets = uint(math.Ceil(float64(elems) / (float64(bucketSize) * loadFactor)))
+	buckets = nextPowerOf2(buckets)
+	fpBits = uint(math.Ceil(math.Log2(2 * float64(bucketSize) / fpr)))
+	return buckets, fpBits
+}
+
+func (f *CuckooFilter[T]) Cap() uint             { return f.buckets * f.bucketSize }
+func (f *CuckooFilter[T]) Count() uint           { return f.count }
+func (f *CuckooFilter[T]) Buckets() uint         { return f.buckets }
+func (f *CuckooFilter[T]) BucketSize() uint      { return f.bucketSize }
+func (f *CuckooFilter[T]) FingerprintBits() uint { return f.fpBits }
+func (f *CuckooFilter[T]) HashAttr() string      { return f.h.String() }
+func (f *CuckooFilter[T]) ByteSize() uint        { return register.GetByteSize(f.r) }
+
+func (f *CuckooFilter[T]) LoadFactor() float64 {
+	return float64(f.count) / float64(f.Cap())
+}
+
+func (f *CuckooFilter[T]) altIndex(index uint, fp uint) uint {
+	return (index ^ (fp * fingerprintMixer)) & (f.buckets - 1)
+}
+
+// primary bucket index and non-zero fingerprint of data
+func (f *CuckooFilter[T]) locate(data []byte) (index uint, fp uint, err error) {
+	hashes, err := f.h.GenerateHash(data, 0, math.MaxUint, 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	index = uint(hashes[0]) & (f.buckets - 1)
+	fp = uint(hashes[1]) & f.r.MaxValue()
+	if fp == 0 {
+		// 0 marks an empty cell
+		fp = 1
+	}
+	return index, fp, nil
+}
+
+// offset of first cell in bucket holding value, false if none
+func (f *CuckooFilter[T]) find(index uint, value uint) (uint, bool) {
+	for i := index * f.bucketSize; i < (index+1)*f.bucketSize; i++ {
+		v, _ := f.r.Read(i)
+		if v == value {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (f *CuckooFilter[T]) Add(data []byte) error {
+	i1, fp, err := f.locate(data)
+	if err != nil {
+		return err
+	}
+	i2 := f.altIndex(i1, fp)
+
+	for _, index := range []uint{i1, i2} {
+		if offset, ok := f.find(index, 0); ok {
+			f.r.Write(offset, fp)
+			f.count++
+			return nil
+		}
+	}
+
+	// both buckets are full -> kick random victims to their alternate bucket
+	// keep a trail so we can roll back and not lose a victim when kicks run out
+	trail := make([]kick, 0, f.maxKicks)
+	index := []uint{i1, i2}[f.rng.Intn(2)]
+	for n := uint(0); n < f.maxKicks; n++ {
+		offset := index*f.bucketSize + uint(f.rng.Intn(int(f.bucketSize)))
+		victim, _ := f.r.Write(offset, fp)
+		trail = append(trail, kick{offset, victim})
+
+		fp = victim
+		index = f.altIndex(index, fp)
+		if offset, ok := f.find(index, 0); ok {
+			f.r.Write(offset, fp)
+			f.count++
+			return nil
+		}
+	}
+
+	for i := len(trail) - 1; i >= 0; i-- {
+		f.r.Write(trail[i].offset, trail[i].value)
+	}
+	return fmt.Errorf(FilterFullMsg, f.maxKicks, f.count)
+}
+
+func (f *CuckooFilter[T]) Contains(data []byte) bool {
+	i1, fp, err := f.locate(data)
+	if err != nil {
+		return false
+	}
+	if _, ok := f.find(i1, fp); ok {
+		return true
+	}
+	_, ok := f.find(f.altIndex(i1, fp), fp)
+	return ok
+}
+
+// removing an item that was never added may delete a colliding fingerprint
+func (f *CuckooFilter[T]) Remove(data []byte) bool {
+	i1, fp, err := f.locate(data)
+	if err != nil {
+		return false
+	}
+	for _, index := range []uint{i1, f.altIndex(i1, fp)} {
+		if offset, ok := f.find(index, fp); ok {
+			f.r.Write(offset, 0)
+			f.count--
+			return true
+		}
+	}
+	return false
+}
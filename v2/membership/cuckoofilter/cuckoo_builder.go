@@ -0,0 +1,89 @@
+package cuckoofilter
+
+import (
+	"math/rand"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+type CuckooFilterBuilder[T hasher.HashOutType] struct {
+	buckets    uint
+	bucketSize uint
+	fpBits     uint
+	maxKicks   uint
+	seed       int64
+	h          hasher.HashGenerator[T]
+	hashErr    error
+}
+
+func NewCuckooFilterBuilder[T hasher.HashOutType]() *CuckooFilterBuilder[T] {
+	defaultBucketSize := uint(4)
+	defaultBuckets, defaultFpBits := CuckooFilterEstimateParams(0.01, 10000, defaultBucketSize)
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &CuckooFilterBuilder[T]{
+		buckets:    defaultBuckets,
+		bucketSize: defaultBucketSize,
+		fpBits:     defaultFpBits,
+		maxKicks:   500,
+		seed:       1,
+		h:          *defaultHasher,
+	}
+}
+
+// rounded up to power of 2
+func (b *CuckooFilterBuilder[T]) SetBuckets(buckets uint) *CuckooFilterBuilder[T] {
+	b.buckets = nextPowerOf2(buckets)
+	return b
+}
+
+func (b *CuckooFilterBuilder[T]) SetBucketSize(bucketSize uint) *CuckooFilterBuilder[T] {
+	b.bucketSize = bucketSize
+	return b
+}
+
+func (b *CuckooFilterBuilder[T]) SetFingerprintBits(fpBits uint) *CuckooFilterBuilder[T] {
+	b.fpBits = fpBits
+	return b
+}
+
+func (b *CuckooFilterBuilder[T]) SetMaxKicks(maxKicks uint) *CuckooFilterBuilder[T] {
+	b.maxKicks = maxKicks
+	return b
+}
+
+// seed of victim selection while kicking
+func (b *CuckooFilterBuilder[T]) SetSeed(seed int64) *CuckooFilterBuilder[T] {
+	b.seed = seed
+	return b
+}
+
+func (b *CuckooFilterBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *CuckooFilterBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *CuckooFilterBuilder[T]) Build() (*CuckooFilter[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	r, err := register.NewRegister(b.buckets*b.bucketSize, b.fpBits)
+	if err != nil {
+		return nil, err
+	}
+	cf := &CuckooFilter[T]{
+		buckets:    b.buckets,
+		bucketSize: b.bucketSize,
+		fpBits:     b.fpBits,
+		maxKicks:   b.maxKicks,
+		r:          r,
+		h:          b.h,
+		rng:        rand.New(rand.NewSource(b.seed)),
+	}
+	return cf, nil
+}
@@ -0,0 +1,487 @@
+package quotientfilter
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// Counting quotient filter (Bender et al., 2012; Pandey et al., 2017)
+// a (q + r)-bit fingerprint is split into quotient fq (home slot) and remainder fr (stored value)
+// remainders sharing a quotient form a sorted run, runs sharing shifted slots form a cluster
+// 3 metadata bits per slot rebuild fq from a shifted remainder:
+//   - occupied: some remainder has this slot as home slot (belongs to slot, not to remainder)
+//   - continuation: remainder is not the 1st of its run
+//   - shifted: remainder is not in its home slot
+//
+// NOTE: counts are stored in a counter register next to remainders
+// instead of the variable-length counter encoding in remainder slots of the CQF paper
+type QuotientFilter[T hasher.HashOutType] struct {
+	qBits      uint
+	rBits      uint
+	size       uint // 2^q slots, circular
+	entries    uint // used slots (distinct fingerprints)
+	count      uint // sum of all counts
+	remainders register.Register
+	metadata   register.Register
+	counts     register.Register
+	h          hasher.HashGenerator[T]
+}
+
+const (
+	FilterFullMsg         = "filter is full (%v slots)"
+	InvalidRemainderMsg   = "invalid remainder bits (%v < %v)"
+	InvalidFingerprintMsg = "invalid fingerprint bits (%v + %v > %v)"
+	MismatchedFilterMsg   = "mismatched filters: (q = %v, r = %v, hash = %s) != (q = %v, r = %v, hash = %s)"
+)
+
+// 1-bit remainders leave the slot metadata no room to tell runs apart
+const MinRemainderBits = 2
+
+const (
+	occupiedBit     uint = 1 << 0
+	continuationBit uint = 1 << 1
+	shiftedBit      uint = 1 << 2
+	metadataBits    uint = 3
+)
+
+type slot struct {
+	meta  uint
+	rem   uint
+	count uint
+}
+
+func (s slot) isOccupied() bool     { return s.meta&occupiedBit != 0 }
+func (s slot) isContinuation() bool { return s.meta&continuationBit != 0 }
+func (s slot) isShifted() bool      { return s.meta&shiftedBit != 0 }
+func (s slot) isEmpty() bool        { return s.meta == 0 }
+
+// remainder is the 1st of its run
+func (s slot) isRunStart() bool {
+	return !s.isContinuation() && (s.isOccupied() || s.isShifted())
+}
+
+// remainder is the 1st of its cluster (sits in its own home slot)
+func (s slot) isClusterStart() bool {
+	return s.isOccupied() && !s.isContinuation() && !s.isShifted()
+}
+
+func (s slot) with(bit uint) slot    { s.meta |= bit; return s }
+func (s slot) without(bit uint) slot { s.meta &^= bit; return s }
+
+// fpr ~ 2^-r at full load, q = ceil(log2(elems / load factor))
+func QuotientFilterEstimateParams(fpr float64, elems uint) (qBits, rBits uint) {
+	qBits = uint(math.Ceil(math.Log2(float64(elems) / 0.75)))
+	rBits = max(MinRemainderBits, uint(math.Ceil(math.Log2(1/fpr))))
+	return qBits, rBits
+}
+
+func newQuotientFilter[T hasher.HashOutType](qBits, rBits, countBits uint, h hasher.HashGenerator[T]) (*QuotientFilter[T], error) {
+	var genericRef T
+	hashBits := uint(8 * unsafe.Sizeof(genericRef))
+	if rBits < MinRemainderBits {
+		return nil, fmt.Errorf(InvalidRemainderMsg, rBits, MinRemainderBits)
+	}
+	if qBits+rBits > hashBits {
+		return nil, fmt.Errorf(InvalidFingerprintMsg, qBits, rBits, hashBits)
+	}
+
+	size := uint(1) << qBits
+	remainders, err := register.NewRegister(size, rBits)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := register.NewRegister(size, metadataBits)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := register.NewRegister(size, countBits)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &QuotientFilter[T]{
+		qBits:      qBits,
+		rBits:      rBits,
+		size:       size,
+		remainders: remainders,
+		metadata:   metadata,
+		counts:     counts,
+		h:          h,
+	}
+	return f, nil
+}
+
+func (f *QuotientFilter[T]) QuotientBits() uint  { return f.qBits }
+func (f *QuotientFilter[T]) RemainderBits() uint { return f.rBits }
+func (f *QuotientFilter[T]) Cap() uint           { return f.size }
+func (f *QuotientFilter[T]) Entries() uint       { return f.entries }
+func (f *QuotientFilter[T]) TotalCount() uint    { return f.count }
+func (f *QuotientFilter[T]) HashAttr() string    { return f.h.String() }
+
+func (f *QuotientFilter[T]) LoadFactor() float64 {
+	return float64(f.entries) / float64(f.size)
+}
+
+func (f *QuotientFilter[T]) ByteSize() uint {
+	return register.GetByteSize(f.remainders) + register.GetByteSize(f.metadata) + register.GetByteSize(f.counts)
+}
+
+func (f *QuotientFilter[T]) incr(i uint) uint { return (i + 1) & (f.size - 1) }
+func (f *QuotientFilter[T]) decr(i uint) uint { return (i - 1) & (f.size - 1) }
+
+func (f *QuotientFilter[T]) get(i uint) slot {
+	meta, _ := f.metadata.Read(i)
+	rem, _ := f.remainders.Read(i)
+	count, _ := f.counts.Read(i)
+	return slot{meta, rem, count}
+}
+
+func (f *QuotientFilter[T]) set(i uint, s slot) {
+	f.metadata.Write(i, s.meta)
+	f.remainders.Write(i, s.rem)
+	f.counts.Write(i, s.count)
+}
+
+func (f *QuotientFilter[T]) fingerprint(data []byte) (uint64, error) {
+	hashes, err := f.h.GenerateHash(data, 0, math.MaxUint, 1)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(hashes[0]) & (1<<(f.qBits+f.rBits) - 1), nil
+}
+
+func (f *QuotientFilter[T]) split(fp uint64) (fq, fr uint) {
+	return uint(fp>>f.rBits) & (f.size - 1), uint(fp) & (1<<f.rBits - 1)
+}
+
+// slot of 1st remainder in run of fq, fq must be occupied
+func (f *QuotientFilter[T]) findRunIndex(fq uint) uint {
+	// walk back to cluster start
+	b := fq
+	for f.get(b).isShifted() {
+		b = f.decr(b)
+	}
+	// walk forward: s skips runs while b skips occupied home slots
+	s := b
+	for b != fq {
+		for {
+			s = f.incr(s)
+			if !f.get(s).isContinuation() {
+				break
+			}
+		}
+		for {
+			b = f.incr(b)
+			if f.get(b).isOccupied() {
+				break
+			}
+		}
+	}
+	return s
+}
+
+// slot holding fr in run of fq
+func (f *QuotientFilter[T]) find(fq, fr uint) (uint, bool) {
+	if !f.get(fq).isOccupied() {
+		return 0, false
+	}
+	s := f.findRunIndex(fq)
+	for {
+		current := f.get(s)
+		if current.rem == fr {
+			return s, true
+		} else if current.rem > fr {
+			// runs are sorted
+			return 0, false
+		}
+		s = f.incr(s)
+		if !f.get(s).isContinuation() {
+			return 0, false
+		}
+	}
+}
+
+// put element at slot i and shift following elements right until an empty slot
+// occupied bits stay with slots
+func (f *QuotientFilter[T]) insertInto(i uint, element slot) {
+	current := element
+	for {
+		prev := f.get(i)
+		empty := prev.isEmpty()
+		if !empty {
+			prev = prev.with(shiftedBit)
+			if prev.isOccupied() {
+				current = current.with(occupiedBit)
+				prev = prev.without(occupiedBit)
+			}
+		}
+		f.set(i, current)
+		current = prev
+		i = f.incr(i)
+		if empty {
+			return
+		}
+	}
+}
+
+func (f *QuotientFilter[T]) insertFingerprint(fp uint64, count uint) error {
+	fq, fr := f.split(fp)
+
+	if s, ok := f.find(fq, fr); ok {
+		current := f.get(s)
+		if count > f.counts.MaxValue()-current.count {
+			return fmt.Errorf(register.ExceedRegisterValueMsg, current.count+count, f.counts.MaxValue())
+		}
+		current.count += count
+		f.set(s, current)
+		f.count += count
+		return nil
+	}
+
+	if count > f.counts.MaxValue() {
+		return fmt.Errorf(register.ExceedRegisterValueMsg, count, f.counts.MaxValue())
+	}
+	if f.entries >= f.size {
+		return fmt.Errorf(FilterFullMsg, f.size)
+	}
+
+	home := f.get(fq)
+	element := slot{rem: fr, count: count}
+
+	if home.isEmpty() {
+		f.set(fq, element.with(occupiedBit))
+		f.entries++
+		f.count += count
+		return nil
+	}
+
+	if !home.isOccupied() {
+		f.set(fq, home.with(occupiedBit))
+	}
+
+	start := f.findRunIndex(fq)
+	s := start
+	if home.isOccupied() {
+		// run exists -> find sorted position in run
+		for {
+			if f.get(s).rem > fr {
+				break
+			}
+			s = f.incr(s)
+			if !f.get(s).isContinuation() {
+				break
+			}
+		}
+		if s == start {
+			// new run start -> old run start becomes continuation
+			f.set(start, f.get(start).with(continuationBit))
+		} else {
+			element = element.with(continuationBit)
+		}
+	}
+	if s != fq {
+		element = element.with(shiftedBit)
+	}
+
+	f.insertInto(s, element)
+	f.entries++
+	f.count += count
+	return nil
+}
+
+// remove slot i and shift the rest of its cluster left
+func (f *QuotientFilter[T]) deleteEntry(i uint, fq uint) {
+	current := f.get(i)
+	next := f.incr(i)
+	origin := i
+
+	for {
+		nextSlot := f.get(next)
+		currentOccupied := current.isOccupied()
+
+		if nextSlot.isEmpty() || nextSlot.isClusterStart() || next == origin {
+			empty := slot{}
+			if currentOccupied {
+				empty = empty.with(occupiedBit)
+			}
+			f.set(i, empty)
+			return
+		}
+
+		updated := nextSlot
+		if nextSlot.isRunStart() {
+			// next run moves 1 step closer to its home slot
+			for {
+				fq = f.incr(fq)
+				if f.get(fq).isOccupied() {
+					break
+				}
+			}
+			if currentOccupied && fq == i {
+				updated = updated.without(shiftedBit)
+			}
+		}
+		if currentOccupied {
+			updated = updated.with(occupiedBit)
+		} else {
+			updated = updated.without(occupiedBit)
+		}
+		f.set(i, updated)
+
+		i = next
+		next = f.incr(next)
+		current = nextSlot
+	}
+}
+
+func (f *QuotientFilter[T]) removeFingerprint(fp uint64, count uint) bool {
+	fq, fr := f.split(fp)
+	s, ok := f.find(fq, fr)
+	if !ok {
+		return false
+	}
+
+	current := f.get(s)
+	if current.count > count {
+		current.count -= count
+		f.set(s, current)
+		f.count -= count
+		return true
+	}
+
+	f.count -= current.count
+	replaceRunStart := current.isRunStart()
+	if replaceRunStart && !f.get(f.incr(s)).isContinuation() {
+		// last remainder of run -> home slot no longer occupied
+		f.set(fq, f.get(fq).without(occupiedBit))
+	}
+
+	f.deleteEntry(s, fq)
+
+	if replaceRunStart {
+		next := f.get(s)
+		updated := next
+		if next.isContinuation() {
+			// next remainder becomes run start
+			updated = updated.without(continuationBit)
+		}
+		if s == fq && updated.isRunStart() {
+			updated = updated.without(shiftedBit)
+		}
+		if updated != next {
+			f.set(s, updated)
+		}
+	}
+	f.entries--
+	return true
+}
+
+// calls fn on every fingerprint with its count, in no particular order
+func (f *QuotientFilter[T]) each(fn func(fp uint64, count uint) error) error {
+	if f.entries == 0 {
+		return nil
+	}
+	start := uint(0)
+	for !f.get(start).isClusterStart() {
+		start++
+	}
+
+	visited := uint(0)
+	fq := start
+	for i := start; visited < f.entries; i = f.incr(i) {
+		current := f.get(i)
+		if current.isClusterStart() {
+			fq = i
+		} else if current.isRunStart() {
+			for {
+				fq = f.incr(fq)
+				if f.get(fq).isOccupied() {
+					break
+				}
+			}
+		}
+		if !current.isEmpty() {
+			visited++
+			fp := uint64(fq)<<f.rBits | uint64(current.rem)
+			if err := fn(fp, current.count); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *QuotientFilter[T]) Add(data []byte) error {
+	return f.AddCount(data, 1)
+}
+
+func (f *QuotientFilter[T]) AddCount(data []byte, count uint) error {
+	fp, err := f.fingerprint(data)
+	if err != nil {
+		return err
+	}
+	return f.insertFingerprint(fp, count)
+}
+
+func (f *QuotientFilter[T]) Contains(data []byte) bool {
+	return f.Count(data) > 0
+}
+
+func (f *QuotientFilter[T]) Count(data []byte) uint {
+	fp, err := f.fingerprint(data)
+	if err != nil {
+		return 0
+	}
+	s, ok := f.find(f.split(fp))
+	if !ok {
+		return 0
+	}
+	return f.get(s).count
+}
+
+// removes 1 occurrence, removing an item that was never added may delete a colliding fingerprint
+func (f *QuotientFilter[T]) Remove(data []byte) bool {
+	fp, err := f.fingerprint(data)
+	if err != nil {
+		return false
+	}
+	return f.removeFingerprint(fp, 1)
+}
+
+// doubles slots by moving 1 remainder bit to quotient, original keys are not needed
+// fingerprint length stays the same so fpr at same load doubles
+func (f *QuotientFilter[T]) Resize() error {
+	if f.rBits <= MinRemainderBits {
+		return fmt.Errorf(InvalidRemainderMsg, f.rBits-1, MinRemainderBits)
+	}
+	resized, err := newQuotientFilter(f.qBits+1, f.rBits-1, f.counts.BitWidth(), f.h)
+	if err != nil {
+		return err
+	}
+	err = f.each(func(fp uint64, count uint) error {
+		return resized.insertFingerprint(fp, count)
+	})
+	if err != nil {
+		return err
+	}
+	*f = *resized
+	return nil
+}
+
+// adds fingerprints and counts of other filter, both must have same q, r and hash
+func (f *QuotientFilter[T]) Merge(other *QuotientFilter[T]) error {
+	if f.qBits != other.qBits || f.rBits != other.rBits || !f.h.Compatible(other.h) {
+		return fmt.Errorf(
+			MismatchedFilterMsg,
+			f.qBits, f.rBits, f.h.String(),
+			other.qBits, other.rBits, other.h.String(),
+		)
+	}
+	return other.each(func(fp uint64, count uint) error {
+		return f.insertFingerprint(fp, count)
+	})
+}
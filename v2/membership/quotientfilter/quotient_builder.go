@@ -0,0 +1,58 @@
+package quotientfilter
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type QuotientFilterBuilder[T hasher.HashOutType] struct {
+	qBits     uint
+	rBits     uint
+	countBits uint
+	h         hasher.HashGenerator[T]
+	hashErr   error
+}
+
+func NewQuotientFilterBuilder[T hasher.HashOutType]() *QuotientFilterBuilder[T] {
+	defaultQBits, defaultRBits := QuotientFilterEstimateParams(0.01, 10000)
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &QuotientFilterBuilder[T]{
+		qBits:     defaultQBits,
+		rBits:     defaultRBits,
+		countBits: 8,
+		h:         *defaultHasher,
+	}
+}
+
+// 2^q slots
+func (b *QuotientFilterBuilder[T]) SetQuotientBits(qBits uint) *QuotientFilterBuilder[T] {
+	b.qBits = qBits
+	return b
+}
+
+// at least MinRemainderBits, odd widths (5, 7, ...) end up in a NonStdBitRegister
+func (b *QuotientFilterBuilder[T]) SetRemainderBits(rBits uint) *QuotientFilterBuilder[T] {
+	b.rBits = rBits
+	return b
+}
+
+func (b *QuotientFilterBuilder[T]) SetCountBits(countBits uint) *QuotientFilterBuilder[T] {
+	b.countBits = countBits
+	return b
+}
+
+func (b *QuotientFilterBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *QuotientFilterBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *QuotientFilterBuilder[T]) Build() (*QuotientFilter[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newQuotientFilter(b.qBits, b.rBits, b.countBits, b.h)
+}
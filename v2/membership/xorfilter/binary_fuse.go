@@ -0,0 +1,91 @@
+package xorfilter
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// 3-wise binary fuse filter (Graf & Lemire, 2022)
+// fingerprints split in small segments, each key maps to 3 consecutive segments
+// ~1.125 * f bits per key for large sets, immutable once built
+type BinaryFuseFilter[T hasher.HashOutType] struct {
+	seed               uint64
+	segmentLength      uint
+	segmentLengthMask  uint
+	segmentCount       uint
+	segmentCountLength uint
+	arrayLength        uint
+	count              uint
+	r                  register.Register
+	h                  hasher.HashGenerator[T]
+}
+
+const (
+	binaryFuseArity            = 3
+	binaryFuseMaxSegmentLength = 262144
+)
+
+// segment layout used by the reference implementation
+func binaryFuseParams(elems uint) (segmentLength, segmentCount, arrayLength uint) {
+	segmentLength = 4
+	if elems > 0 {
+		segmentLength = 1 << int(math.Floor(math.Log(float64(elems))/math.Log(3.33)+2.25))
+	}
+	if segmentLength > binaryFuseMaxSegmentLength {
+		segmentLength = binaryFuseMaxSegmentLength
+	}
+
+	capacity := 0
+	if elems > 1 {
+		sizeFactor := math.Max(1.125, 0.875+0.25*math.Log(1000000)/math.Log(float64(elems)))
+		capacity = int(math.Round(float64(elems) * sizeFactor))
+	}
+	l := int(segmentLength)
+	initSegmentCount := (capacity+l-1)/l - (binaryFuseArity - 1)
+	length := (initSegmentCount + binaryFuseArity - 1) * l
+	count := (length + l - 1) / l
+	if count <= binaryFuseArity-1 {
+		count = 1
+	} else {
+		count -= binaryFuseArity - 1
+	}
+
+	segmentCount = uint(count)
+	arrayLength = (segmentCount + binaryFuseArity - 1) * segmentLength
+	return segmentLength, segmentCount, arrayLength
+}
+
+func (f *BinaryFuseFilter[T]) positions(hash uint64) [3]uint {
+	hi, _ := bits.Mul64(hash, uint64(f.segmentCountLength))
+	h0 := uint(hi)
+	h1 := h0 + f.segmentLength
+	h2 := h1 + f.segmentLength
+	h1 ^= uint(hash>>18) & f.segmentLengthMask
+	h2 ^= uint(hash) & f.segmentLengthMask
+	return [3]uint{h0, h1, h2}
+}
+
+func (f *BinaryFuseFilter[T]) Cap() uint             { return f.arrayLength }
+func (f *BinaryFuseFilter[T]) Count() uint           { return f.count }
+func (f *BinaryFuseFilter[T]) FingerprintBits() uint { return f.r.BitWidth() }
+func (f *BinaryFuseFilter[T]) HashAttr() string      { return f.h.String() }
+func (f *BinaryFuseFilter[T]) ByteSize() uint        { return register.GetByteSize(f.r) }
+
+// 0 for a filter of no keys
+func (f *BinaryFuseFilter[T]) BitsPerEntry() float64 {
+	if f.count == 0 {
+		return 0
+	}
+	return float64(f.Cap()*f.r.BitWidth()) / float64(f.count)
+}
+
+func (f *BinaryFuseFilter[T]) Contains(data []byte) bool {
+	hashes, err := f.h.GenerateHash(data, 0, math.MaxUint, 1)
+	if err != nil {
+		return false
+	}
+	return lookup(mix64(uint64(hashes[0])+f.seed), f.positions, f.r)
+}
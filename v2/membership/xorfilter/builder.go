@@ -0,0 +1,122 @@
+package xorfilter
+
+import (
+	"fmt"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// shared by xor and binary fuse filters since both are built in 1 shot from a key set
+type StaticFilterBuilder[T hasher.HashOutType] struct {
+	fpBits      uint
+	seed        uint64
+	maxAttempts uint
+	h           hasher.HashGenerator[T]
+	hashErr     error
+}
+
+func NewStaticFilterBuilder[T hasher.HashOutType]() *StaticFilterBuilder[T] {
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &StaticFilterBuilder[T]{
+		fpBits:      8,
+		seed:        1,
+		maxAttempts: 100,
+		h:           *defaultHasher,
+	}
+}
+
+// 8 (fpr ~ 0.39%) or 16 (fpr ~ 0.0015%)
+func (b *StaticFilterBuilder[T]) SetFingerprintBits(fpBits uint) *StaticFilterBuilder[T] {
+	b.fpBits = fpBits
+	return b
+}
+
+// seed of the generator deriving per-attempt seeds
+func (b *StaticFilterBuilder[T]) SetSeed(seed uint64) *StaticFilterBuilder[T] {
+	b.seed = seed
+	return b
+}
+
+func (b *StaticFilterBuilder[T]) SetMaxAttempts(maxAttempts uint) *StaticFilterBuilder[T] {
+	b.maxAttempts = maxAttempts
+	return b
+}
+
+func (b *StaticFilterBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *StaticFilterBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+// peel keys w/ a fresh seed until it succeeds or attempts run out
+func (b *StaticFilterBuilder[T]) build(keys [][]byte, arrayLength uint, positions func(hash uint64) [3]uint) (r register.Register, seed uint64, err error) {
+	if err = checkFingerprintBits(b.fpBits); err != nil {
+		return nil, 0, err
+	}
+	baseHashes, err := hashKeys(b.h, keys)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hashes := make([]uint64, len(baseHashes))
+	state := b.seed
+	for attempt := uint(0); attempt < b.maxAttempts; attempt++ {
+		seed = splitmix64(&state)
+		for i, hash := range baseHashes {
+			hashes[i] = mix64(hash + seed)
+		}
+		r, err = register.NewRegister(arrayLength, b.fpBits)
+		if err != nil {
+			return nil, 0, err
+		}
+		if construct(hashes, arrayLength, positions, r) {
+			return r, seed, nil
+		}
+	}
+	return nil, 0, fmt.Errorf(ConstructionFailedMsg, b.maxAttempts)
+}
+
+func (b *StaticFilterBuilder[T]) BuildXor(keys [][]byte) (*XorFilter[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	arrayLength := xorFilterArrayLength(uint(len(keys)))
+	f := &XorFilter[T]{
+		blockLength: arrayLength / 3,
+		count:       uint(len(keys)),
+		h:           b.h,
+	}
+	r, seed, err := b.build(keys, 3*f.blockLength, f.positions)
+	if err != nil {
+		return nil, err
+	}
+	f.r, f.seed = r, seed
+	return f, nil
+}
+
+func (b *StaticFilterBuilder[T]) BuildBinaryFuse(keys [][]byte) (*BinaryFuseFilter[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	segmentLength, segmentCount, arrayLength := binaryFuseParams(uint(len(keys)))
+	f := &BinaryFuseFilter[T]{
+		segmentLength:      segmentLength,
+		segmentLengthMask:  segmentLength - 1,
+		segmentCount:       segmentCount,
+		segmentCountLength: segmentCount * segmentLength,
+		arrayLength:        arrayLength,
+		count:              uint(len(keys)),
+		h:                  b.h,
+	}
+	r, seed, err := b.build(keys, arrayLength, f.positions)
+	if err != nil {
+		return nil, err
+	}
+	f.r, f.seed = r, seed
+	return f, nil
+}
@@ -0,0 +1,144 @@
+package xorfilter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+const (
+	DuplicateKeyMsg          = "duplicate key at index %v (%q)"
+	ConstructionFailedMsg    = "can't construct filter after %v attempts"
+	InvalidFingerprintBitMsg = "invalid fingerprint bits (%v not in [8, 16])"
+)
+
+// murmur3 finalizer, mixes seed into precomputed key hash on every attempt
+// so we don't have to rehash keys (some families ignore the seed)
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// splitmix64 generator for attempt seeds
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func fingerprint(hash uint64) uint64 {
+	return hash ^ (hash >> 32)
+}
+
+func checkFingerprintBits(fpBits uint) error {
+	if fpBits != 8 && fpBits != 16 {
+		return fmt.Errorf(InvalidFingerprintBitMsg, fpBits)
+	}
+	return nil
+}
+
+// hash every key once, reject duplicates since they never peel
+// distinct keys of equal hash (likely with 32-bit hashes) are 1 key to Contains, keep the 1st
+func hashKeys[T hasher.HashOutType](h hasher.HashGenerator[T], keys [][]byte) ([]uint64, error) {
+	seen := make(map[string]struct{}, len(keys))
+	seenHashes := make(map[uint64]struct{}, len(keys))
+	hashes := make([]uint64, 0, len(keys))
+	for i, key := range keys {
+		if _, ok := seen[string(key)]; ok {
+			return nil, fmt.Errorf(DuplicateKeyMsg, i, key)
+		}
+		seen[string(key)] = struct{}{}
+
+		keyHashes, err := h.GenerateHash(key, 0, math.MaxUint, 1)
+		if err != nil {
+			return nil, err
+		}
+		hash := uint64(keyHashes[0])
+		if _, ok := seenHashes[hash]; ok {
+			continue
+		}
+		seenHashes[hash] = struct{}{}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// 3-hypergraph peeling (Graf & Lemire, 2020)
+// keep removing slots hit by exactly 1 key, then assign fingerprints in reverse peeling order
+// so that fp(x) = B[h0(x)] ^ B[h1(x)] ^ B[h2(x)] holds for every key
+func construct(hashes []uint64, arrayLength uint, positions func(hash uint64) [3]uint, r register.Register) bool {
+	xorMask := make([]uint64, arrayLength)
+	counts := make([]uint32, arrayLength)
+	for _, hash := range hashes {
+		for _, p := range positions(hash) {
+			xorMask[p] ^= hash
+			counts[p]++
+		}
+	}
+
+	queue := make([]uint, 0, arrayLength)
+	for p := uint(0); p < arrayLength; p++ {
+		if counts[p] == 1 {
+			queue = append(queue, p)
+		}
+	}
+
+	type peeled struct {
+		hash  uint64
+		index uint
+	}
+	stack := make([]peeled, 0, len(hashes))
+	for len(queue) > 0 {
+		p := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if counts[p] != 1 {
+			continue
+		}
+		// only 1 key left in this slot -> xor mask is its hash
+		hash := xorMask[p]
+		stack = append(stack, peeled{hash, p})
+		for _, q := range positions(hash) {
+			xorMask[q] ^= hash
+			counts[q]--
+			if counts[q] == 1 {
+				queue = append(queue, q)
+			}
+		}
+	}
+
+	if len(stack) != len(hashes) {
+		// cycle in hypergraph -> retry with another seed
+		return false
+	}
+
+	mask := uint64(r.MaxValue())
+	for i := len(stack) - 1; i >= 0; i-- {
+		fp := fingerprint(stack[i].hash) & mask
+		for _, q := range positions(stack[i].hash) {
+			if q == stack[i].index {
+				continue
+			}
+			v, _ := r.Read(q)
+			fp ^= uint64(v)
+		}
+		r.Write(stack[i].index, uint(fp))
+	}
+	return true
+}
+
+func lookup(hash uint64, positions func(hash uint64) [3]uint, r register.Register) bool {
+	fp := fingerprint(hash) & uint64(r.MaxValue())
+	for _, q := range positions(hash) {
+		v, _ := r.Read(q)
+		fp ^= uint64(v)
+	}
+	return fp == 0
+}
@@ -0,0 +1,59 @@
+package xorfilter
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// Xor filter (Graf & Lemire, 2020)
+// 1.23n fingerprints split in 3 blocks, each key maps to 1 slot per block
+// ~1.23 * f bits per key for fpr = 2^-f, immutable once built
+type XorFilter[T hasher.HashOutType] struct {
+	seed        uint64
+	blockLength uint
+	count       uint
+	r           register.Register
+	h           hasher.HashGenerator[T]
+}
+
+func xorFilterArrayLength(elems uint) uint {
+	return uint(math.Floor(1.23*float64(elems))) + 32
+}
+
+// Lemire's multiply-shift, maps hash to [0, n) w/o division
+func reduce(hash uint32, n uint32) uint32 {
+	return uint32((uint64(hash) * uint64(n)) >> 32)
+}
+
+func (f *XorFilter[T]) positions(hash uint64) [3]uint {
+	blockLength := uint32(f.blockLength)
+	h0 := uint(reduce(uint32(hash), blockLength))
+	h1 := uint(reduce(uint32(bits.RotateLeft64(hash, 21)), blockLength)) + f.blockLength
+	h2 := uint(reduce(uint32(bits.RotateLeft64(hash, 42)), blockLength)) + 2*f.blockLength
+	return [3]uint{h0, h1, h2}
+}
+
+func (f *XorFilter[T]) Cap() uint             { return 3 * f.blockLength }
+func (f *XorFilter[T]) Count() uint           { return f.count }
+func (f *XorFilter[T]) FingerprintBits() uint { return f.r.BitWidth() }
+func (f *XorFilter[T]) HashAttr() string      { return f.h.String() }
+func (f *XorFilter[T]) ByteSize() uint        { return register.GetByteSize(f.r) }
+
+// 0 for a filter of no keys
+func (f *XorFilter[T]) BitsPerEntry() float64 {
+	if f.count == 0 {
+		return 0
+	}
+	return float64(f.Cap()*f.r.BitWidth()) / float64(f.count)
+}
+
+func (f *XorFilter[T]) Contains(data []byte) bool {
+	hashes, err := f.h.GenerateHash(data, 0, math.MaxUint, 1)
+	if err != nil {
+		return false
+	}
+	return lookup(mix64(uint64(hashes[0])+f.seed), f.positions, f.r)
+}
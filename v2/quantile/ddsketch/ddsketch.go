@@ -0,0 +1,214 @@
+package ddsketch
+
+import (
+	"fmt"
+	"math"
+)
+
+// DDSketch (Masson, Rim & Lee, 2019)
+// value x > 0 goes to bucket i = ceil(log_gamma(x)) with gamma = (1 + alpha) / (1 - alpha)
+// every value in (gamma^(i-1), gamma^i] is within relative error alpha of 2 * gamma^i / (gamma + 1)
+// so any quantile is returned with relative accuracy alpha, as long as its bucket wasn't collapsed
+// negative values are mapped by magnitude into a separate store, values too close to 0 are counted apart
+type DDSketch struct {
+	alpha             float64
+	gamma             float64
+	logGamma          float64
+	minIndexableValue float64
+	positive          *denseStore
+	negative          *denseStore
+	zeroCount         uint64
+	min               float64
+	max               float64
+	sum               float64
+}
+
+const (
+	InvalidRelativeAccuracyMsg = "invalid relative accuracy (%v not in (0, 1))"
+	InvalidMaxBucketsMsg       = "invalid max buckets (%v <= 0)"
+	InvalidValueMsg            = "invalid value (%v)"
+	InvalidQuantileMsg         = "invalid quantile (%v not in [0, 1])"
+	EmptySketchMsg             = "sketch is empty"
+	MismatchedSketchMsg        = "can't merge sketches with different relative accuracy (%v != %v)"
+)
+
+func newDDSketch(alpha float64, maxBuckets uint, counterBits uint) (*DDSketch, error) {
+	if !(alpha > 0 && alpha < 1) {
+		return nil, fmt.Errorf(InvalidRelativeAccuracyMsg, alpha)
+	}
+	if maxBuckets == 0 {
+		return nil, fmt.Errorf(InvalidMaxBucketsMsg, maxBuckets)
+	}
+	positive, err := newDenseStore(maxBuckets, counterBits)
+	if err != nil {
+		return nil, err
+	}
+	negative, _ := newDenseStore(maxBuckets, counterBits)
+	gamma := (1 + alpha) / (1 - alpha)
+	logGamma := math.Log(gamma)
+	d := &DDSketch{
+		alpha:    alpha,
+		gamma:    gamma,
+		logGamma: logGamma,
+		// keep bucket indexes inside int32 and away from subnormals
+		minIndexableValue: math.Max(math.Exp((math.MinInt32+1)*logGamma), 0x1p-1022*gamma),
+		positive:          positive,
+		negative:          negative,
+		min:               math.Inf(1),
+		max:               math.Inf(-1),
+	}
+	return d, nil
+}
+
+func (d *DDSketch) RelativeAccuracy() float64 { return d.alpha }
+func (d *DDSketch) Sum() float64              { return d.sum }
+
+func (d *DDSketch) Count() uint64 {
+	return d.positive.count + d.negative.count + d.zeroCount
+}
+
+func (d *DDSketch) IsEmpty() bool { return d.Count() == 0 }
+
+// true once the lowest buckets of either store were folded, low quantiles lose the accuracy guarantee
+func (d *DDSketch) IsCollapsed() bool {
+	return d.positive.collapsed || d.negative.collapsed
+}
+
+func (d *DDSketch) ByteSize() uint {
+	return d.positive.byteSize() + d.negative.byteSize()
+}
+
+func (d *DDSketch) Min() (float64, error) {
+	if d.IsEmpty() {
+		return 0, fmt.Errorf(EmptySketchMsg)
+	}
+	return d.min, nil
+}
+
+func (d *DDSketch) Max() (float64, error) {
+	if d.IsEmpty() {
+		return 0, fmt.Errorf(EmptySketchMsg)
+	}
+	return d.max, nil
+}
+
+func (d *DDSketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / d.logGamma))
+}
+
+// representative value of bucket index, relative error <= alpha for the whole bucket
+func (d *DDSketch) value(index int) float64 {
+	return 2 * math.Exp(float64(index)*d.logGamma) / (d.gamma + 1)
+}
+
+func (d *DDSketch) Add(value float64) error {
+	return d.AddCount(value, 1)
+}
+
+func (d *DDSketch) AddCount(value float64, n uint) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf(InvalidValueMsg, value)
+	}
+	if n == 0 {
+		return nil
+	}
+	var err error
+	switch {
+	case value > d.minIndexableValue:
+		err = d.positive.add(d.index(value), n)
+	case value < -d.minIndexableValue:
+		err = d.negative.add(d.index(-value), n)
+	default:
+		d.zeroCount += uint64(n)
+	}
+	if err != nil {
+		return err
+	}
+	d.min = math.Min(d.min, value)
+	d.max = math.Max(d.max, value)
+	d.sum += value * float64(n)
+	return nil
+}
+
+// lower quantile: value of rank floor(q * (count - 1)), clamped to the exact [min, max]
+// q = 0 and q = 1 return the exact min and max
+func (d *DDSketch) Quantile(q float64) (float64, error) {
+	if !(q >= 0 && q <= 1) {
+		return 0, fmt.Errorf(InvalidQuantileMsg, q)
+	}
+	if d.IsEmpty() {
+		return 0, fmt.Errorf(EmptySketchMsg)
+	}
+	if q == 0 {
+		return d.min, nil
+	}
+	if q == 1 {
+		return d.max, nil
+	}
+	rank := uint64(q * float64(d.Count()-1))
+
+	var result float64
+	found := false
+	seen := uint64(0)
+	// most negative first -> highest index of the negative store
+	d.negative.forEach(true, func(index int, count uint) bool {
+		seen += uint64(count)
+		if seen > rank {
+			result, found = -d.value(index), true
+		}
+		return !found
+	})
+	if !found {
+		seen += d.zeroCount
+		if seen > rank {
+			result, found = 0, true
+		}
+	}
+	if !found {
+		d.positive.forEach(false, func(index int, count uint) bool {
+			seen += uint64(count)
+			if seen > rank {
+				result, found = d.value(index), true
+			}
+			return !found
+		})
+	}
+	if !found {
+		// only reachable if counters saturated, fall back to the largest value
+		result = d.max
+	}
+	return math.Max(d.min, math.Min(d.max, result)), nil
+}
+
+func (d *DDSketch) Quantiles(qs []float64) ([]float64, error) {
+	values := make([]float64, len(qs))
+	for i, q := range qs {
+		value, err := d.Quantile(q)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// bucket-wise sum, lossless as long as neither store has to collapse
+func (d *DDSketch) Merge(other *DDSketch) error {
+	if d.gamma != other.gamma {
+		return fmt.Errorf(MismatchedSketchMsg, d.alpha, other.alpha)
+	}
+	if other.IsEmpty() {
+		return nil
+	}
+	if err := d.positive.merge(other.positive); err != nil {
+		return err
+	}
+	if err := d.negative.merge(other.negative); err != nil {
+		return err
+	}
+	d.zeroCount += other.zeroCount
+	d.min = math.Min(d.min, other.min)
+	d.max = math.Max(d.max, other.max)
+	d.sum += other.sum
+	return nil
+}
@@ -0,0 +1,37 @@
+package ddsketch
+
+type DDSketchBuilder struct {
+	alpha       float64
+	maxBuckets  uint
+	counterBits uint
+}
+
+func NewDDSketchBuilder() *DDSketchBuilder {
+	return &DDSketchBuilder{
+		alpha: 0.01,
+		// with alpha = 1% covers ~ 1ns to ~ 10^8 s per store before collapsing
+		maxBuckets:  2048,
+		counterBits: 32,
+	}
+}
+
+func (b *DDSketchBuilder) SetRelativeAccuracy(alpha float64) *DDSketchBuilder {
+	b.alpha = alpha
+	return b
+}
+
+// buckets per store, lowest buckets are collapsed beyond this
+func (b *DDSketchBuilder) SetMaxBuckets(maxBuckets uint) *DDSketchBuilder {
+	b.maxBuckets = maxBuckets
+	return b
+}
+
+// counters saturate at 2^bits - 1
+func (b *DDSketchBuilder) SetCounterBits(counterBits uint) *DDSketchBuilder {
+	b.counterBits = counterBits
+	return b
+}
+
+func (b *DDSketchBuilder) Build() (*DDSketch, error) {
+	return newDDSketch(b.alpha, b.maxBuckets, b.counterBits)
+}
@@ -0,0 +1,165 @@
+package ddsketch
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+// contiguous bucket counters for indexes [minIndex, maxIndex]
+// register slot i holds bucket offset + i, the register is reallocated when the range outgrows it
+// once the range would span more than maxBuckets, the lowest buckets are folded into the lowest kept one
+type denseStore struct {
+	r           register.Register
+	counterBits uint
+	maxBuckets  uint
+	offset      int
+	minIndex    int
+	maxIndex    int
+	count       uint64
+	collapsed   bool
+}
+
+// initial register size, doubles on growth
+const initialBuckets = 64
+
+func newDenseStore(maxBuckets, counterBits uint) (*denseStore, error) {
+	r, err := register.NewRegister(min(maxBuckets, initialBuckets), counterBits)
+	if err != nil {
+		return nil, err
+	}
+	return &denseStore{r: r, maxBuckets: maxBuckets, counterBits: counterBits}, nil
+}
+
+func (s *denseStore) isEmpty() bool { return s.count == 0 }
+
+func (s *denseStore) byteSize() uint {
+	return register.GetByteSize(s.r)
+}
+
+func (s *denseStore) saturatingAdd(value, n uint) uint {
+	if n > s.r.MaxValue()-value {
+		return s.r.MaxValue()
+	}
+	return value + n
+}
+
+// bucket count, 0 outside of range
+func (s *denseStore) get(index int) uint {
+	if s.isEmpty() || index < s.minIndex || index > s.maxIndex {
+		return 0
+	}
+	v, _ := s.r.Read(uint(index - s.offset))
+	return v
+}
+
+func (s *denseStore) add(index int, n uint) error {
+	if n == 0 {
+		return nil
+	}
+	newMin, newMax := index, index
+	if !s.isEmpty() {
+		newMin, newMax = min(newMin, s.minIndex), max(newMax, s.maxIndex)
+	}
+	if uint(newMax-newMin+1) > s.maxBuckets {
+		newMin = newMax - int(s.maxBuckets) + 1
+		index = max(index, newMin)
+	}
+	if err := s.reshape(newMin, newMax); err != nil {
+		return err
+	}
+
+	slot := uint(index - s.offset)
+	v, _ := s.r.Read(slot)
+	after := s.saturatingAdd(v, n)
+	if _, err := s.r.Write(slot, after); err != nil {
+		return err
+	}
+	s.count += uint64(after - v)
+	return nil
+}
+
+// make the store cover [newMin, newMax], folding buckets below newMin into newMin
+func (s *denseStore) reshape(newMin, newMax int) error {
+	if s.isEmpty() {
+		s.offset, s.minIndex, s.maxIndex = newMin, newMin, newMax
+		return nil
+	}
+
+	capacity := s.r.Capacity()
+	fits := newMin >= s.offset && newMax < s.offset+int(capacity)
+	if fits && newMin <= s.minIndex {
+		// slots outside of the current range are always 0
+		s.minIndex, s.maxIndex = newMin, newMax
+		return nil
+	}
+
+	r := s.r
+	offset := s.offset
+	if !fits {
+		span := uint(newMax - newMin + 1)
+		capacity = min(s.maxBuckets, max(span, 2*capacity))
+		var err error
+		if r, err = register.NewRegister(capacity, s.counterBits); err != nil {
+			return err
+		}
+		// leave the slack on the side we are growing towards
+		offset = newMin
+		if newMin < s.minIndex {
+			offset = newMax - int(capacity) + 1
+		}
+	}
+
+	for index := s.minIndex; index <= s.maxIndex; index++ {
+		v, _ := s.r.Read(uint(index - s.offset))
+		if v == 0 {
+			continue
+		}
+		target := max(index, newMin)
+		if target != index {
+			s.collapsed = true
+		}
+		if r == s.r && target == index {
+			continue
+		}
+		if r == s.r {
+			s.r.Write(uint(index-s.offset), 0)
+		}
+		slot := uint(target - offset)
+		w, _ := r.Read(slot)
+		sum := s.saturatingAdd(w, v)
+		r.Write(slot, sum)
+		s.count -= uint64(v - (sum - w))
+	}
+	s.r, s.offset, s.minIndex, s.maxIndex = r, offset, newMin, newMax
+	return nil
+}
+
+func (s *denseStore) merge(other *denseStore) error {
+	if other.isEmpty() {
+		return nil
+	}
+	for index := other.minIndex; index <= other.maxIndex; index++ {
+		if err := s.add(index, other.get(index)); err != nil {
+			return err
+		}
+	}
+	if other.collapsed {
+		s.collapsed = true
+	}
+	return nil
+}
+
+// visit non-empty buckets in ascending (or descending) index order until fn returns false
+func (s *denseStore) forEach(descending bool, fn func(index int, count uint) bool) {
+	if s.isEmpty() {
+		return
+	}
+	for i := s.minIndex; i <= s.maxIndex; i++ {
+		index := i
+		if descending {
+			index = s.maxIndex - (i - s.minIndex)
+		}
+		if count := s.get(index); count > 0 && !fn(index, count) {
+			return
+		}
+	}
+}
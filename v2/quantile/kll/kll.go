@@ -0,0 +1,315 @@
+package kll
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// KLL sketch (Karnin, Lang & Liberty, 2016)
+// a stack of compactors, items at level h carry weight 2^h
+// a full compactor is sorted and every other item (random offset) is promoted to the next level
+// capacities shrink geometrically (by c = 2/3) going down from the top level
+// rank error is eps ~ 1 / k with high probability, independent of input distribution
+type KLLSketch[T cmp.Ordered] struct {
+	k         uint
+	n         uint64
+	levels    [][]T // levels[h] holds items of weight 2^h
+	retained  uint  // items across all levels
+	caps      []uint
+	totalCap  uint // sum of caps, recomputed with them when k or the level count change
+	minItem   T
+	maxItem   T
+	rng       *rand.Rand
+	sortedBuf []weightedItem[T]
+	sorted    bool
+}
+
+type weightedItem[T cmp.Ordered] struct {
+	item   T
+	weight uint64 // cumulative weight once sorted
+}
+
+const (
+	InvalidKMsg        = "invalid k (%v not in [%v, %v])"
+	InvalidQuantileMsg = "invalid quantile (%v not in [0, 1])"
+	EmptySketchMsg     = "sketch is empty"
+	UnsortedSplitsMsg  = "split points must be unique and increasing"
+)
+
+const (
+	MinK     = 8
+	MaxK     = 65535
+	DefaultK = 200
+	// smallest compactor, lower levels never shrink below this
+	minWidth = 8
+	// capacity decay per level
+	capacityDecay = 2.0 / 3.0
+)
+
+func NewKLLSketch[T cmp.Ordered](k uint, seed int64) (*KLLSketch[T], error) {
+	if k < MinK || k > MaxK {
+		return nil, fmt.Errorf(InvalidKMsg, k, MinK, MaxK)
+	}
+	s := &KLLSketch[T]{
+		k:      k,
+		levels: [][]T{make([]T, 0, k)},
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+	s.updateCapacities()
+	return s, nil
+}
+
+func (s *KLLSketch[T]) K() uint         { return s.k }
+func (s *KLLSketch[T]) Count() uint64   { return s.n }
+func (s *KLLSketch[T]) IsEmpty() bool   { return s.n == 0 }
+func (s *KLLSketch[T]) NumLevels() uint { return uint(len(s.levels)) }
+
+// items retained across all levels
+func (s *KLLSketch[T]) NumRetained() uint { return s.retained }
+
+func (s *KLLSketch[T]) Min() (T, error) {
+	if s.n == 0 {
+		var zero T
+		return zero, fmt.Errorf(EmptySketchMsg)
+	}
+	return s.minItem, nil
+}
+
+func (s *KLLSketch[T]) Max() (T, error) {
+	if s.n == 0 {
+		var zero T
+		return zero, fmt.Errorf(EmptySketchMsg)
+	}
+	return s.maxItem, nil
+}
+
+// normalized rank error for k at 99% confidence (empirical fit used by Apache DataSketches)
+// pmf = true gives the (larger) error of PMF/CDF queries over several split points
+func NormalizedRankError(k uint, pmf bool) float64 {
+	if pmf {
+		return 2.446 / math.Pow(float64(k), 0.9433)
+	}
+	return 2.296 / math.Pow(float64(k), 0.9723)
+}
+
+func (s *KLLSketch[T]) NormalizedRankError(pmf bool) float64 {
+	return NormalizedRankError(s.k, pmf)
+}
+
+// capacity of every level, they depend on k and the depth below the top level only
+func (s *KLLSketch[T]) updateCapacities() {
+	s.caps = s.caps[:0]
+	s.totalCap = 0
+	for h := range s.levels {
+		depth := len(s.levels) - 1 - h
+		capacity := max(minWidth, uint(math.Round(float64(s.k)*math.Pow(capacityDecay, float64(depth)))))
+		s.caps = append(s.caps, capacity)
+		s.totalCap += capacity
+	}
+}
+
+func (s *KLLSketch[T]) addLevel() {
+	s.levels = append(s.levels, nil)
+	s.updateCapacities()
+}
+
+func (s *KLLSketch[T]) Update(item T) {
+	// NaN has no place in the order, drop it
+	if item != item {
+		return
+	}
+	if s.n == 0 {
+		s.minItem, s.maxItem = item, item
+	} else {
+		s.minItem = min(s.minItem, item)
+		s.maxItem = max(s.maxItem, item)
+	}
+	s.n++
+	s.levels[0] = append(s.levels[0], item)
+	s.retained++
+	s.sorted = false
+	if s.retained >= s.totalCap {
+		s.compress()
+	}
+}
+
+// compact the lowest full level until the sketch fits again
+func (s *KLLSketch[T]) compress() {
+	for s.retained >= s.totalCap {
+		for h := range s.levels {
+			if uint(len(s.levels[h])) < s.caps[h] {
+				continue
+			}
+			if h+1 == len(s.levels) {
+				s.addLevel()
+			}
+			s.compact(h)
+			break
+		}
+	}
+}
+
+// sort level h and promote every other item to h + 1
+// with an odd count the largest item stays behind so weight is preserved
+func (s *KLLSketch[T]) compact(h int) {
+	level := s.levels[h]
+	slices.Sort(level)
+	var leftover []T
+	if len(level)%2 == 1 {
+		leftover = []T{level[len(level)-1]}
+		level = level[:len(level)-1]
+	}
+	offset := s.rng.Intn(2)
+	for i := offset; i < len(level); i += 2 {
+		s.levels[h+1] = append(s.levels[h+1], level[i])
+	}
+	s.levels[h] = append(s.levels[h][:0], leftover...)
+	s.retained -= uint(len(level) / 2)
+}
+
+// combine other into s, other is left untouched
+// sketches of different k merge to the smaller k
+func (s *KLLSketch[T]) Merge(other *KLLSketch[T]) {
+	if other.n == 0 {
+		return
+	}
+	if s.n == 0 {
+		s.minItem, s.maxItem = other.minItem, other.maxItem
+	} else {
+		s.minItem = min(s.minItem, other.minItem)
+		s.maxItem = max(s.maxItem, other.maxItem)
+	}
+	s.k = min(s.k, other.k)
+	s.n += other.n
+	for len(s.levels) < len(other.levels) {
+		s.levels = append(s.levels, nil)
+	}
+	s.updateCapacities()
+	for h, level := range other.levels {
+		s.levels[h] = append(s.levels[h], level...)
+	}
+	s.retained += other.retained
+	s.sorted = false
+	s.compress()
+}
+
+// all retained items in order, weight holds cumulative weight up to and including the item
+func (s *KLLSketch[T]) sortedView() []weightedItem[T] {
+	if s.sorted {
+		return s.sortedBuf
+	}
+	view := s.sortedBuf[:0]
+	for h, level := range s.levels {
+		for _, item := range level {
+			view = append(view, weightedItem[T]{item, 1 << h})
+		}
+	}
+	slices.SortStableFunc(view, func(a, b weightedItem[T]) int { return cmp.Compare(a.item, b.item) })
+	cumulative := uint64(0)
+	for i := range view {
+		cumulative += view[i].weight
+		view[i].weight = cumulative
+	}
+	s.sortedBuf = view
+	s.sorted = true
+	return view
+}
+
+// normalized rank of item: estimated fraction of stream <= item
+func (s *KLLSketch[T]) Rank(item T) float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	view := s.sortedView()
+	// first retained item > item
+	i, _ := slices.BinarySearchFunc(view, item, func(w weightedItem[T], target T) int {
+		if w.item <= target {
+			return -1
+		}
+		return 1
+	})
+	if i == 0 {
+		return 0
+	}
+	return float64(view[i-1].weight) / float64(s.n)
+}
+
+// smallest retained item whose normalized rank >= q
+// q = 0 and q = 1 return the exact min and max
+func (s *KLLSketch[T]) Quantile(q float64) (T, error) {
+	var zero T
+	if !(q >= 0 && q <= 1) {
+		return zero, fmt.Errorf(InvalidQuantileMsg, q)
+	}
+	if s.n == 0 {
+		return zero, fmt.Errorf(EmptySketchMsg)
+	}
+	if q == 0 {
+		return s.minItem, nil
+	}
+	if q == 1 {
+		return s.maxItem, nil
+	}
+	view := s.sortedView()
+	target := uint64(math.Ceil(q * float64(s.n)))
+	i, _ := slices.BinarySearchFunc(view, target, func(w weightedItem[T], target uint64) int {
+		return cmp.Compare(w.weight, target)
+	})
+	if i == len(view) {
+		i--
+	}
+	return view[i].item, nil
+}
+
+func (s *KLLSketch[T]) Quantiles(qs []float64) ([]T, error) {
+	items := make([]T, len(qs))
+	for i, q := range qs {
+		item, err := s.Quantile(q)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func checkSplits[T cmp.Ordered](splits []T) error {
+	for i := range splits {
+		if splits[i] != splits[i] || (i > 0 && splits[i-1] >= splits[i]) {
+			return fmt.Errorf(UnsortedSplitsMsg)
+		}
+	}
+	return nil
+}
+
+// normalized ranks at every split point plus a trailing 1
+// cdf[i] ~ fraction of stream <= splits[i]
+func (s *KLLSketch[T]) CDF(splits []T) ([]float64, error) {
+	if err := checkSplits(splits); err != nil {
+		return nil, err
+	}
+	if s.n == 0 {
+		return nil, fmt.Errorf(EmptySketchMsg)
+	}
+	cdf := make([]float64, len(splits)+1)
+	for i, split := range splits {
+		cdf[i] = s.Rank(split)
+	}
+	cdf[len(splits)] = 1
+	return cdf, nil
+}
+
+// mass of each interval (-inf, s0], (s0, s1], ..., (sm-1, +inf)
+func (s *KLLSketch[T]) PMF(splits []T) ([]float64, error) {
+	pmf, err := s.CDF(splits)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(pmf) - 1; i > 0; i-- {
+		pmf[i] -= pmf[i-1]
+	}
+	return pmf, nil
+}
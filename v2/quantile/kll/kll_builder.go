@@ -0,0 +1,38 @@
+package kll
+
+import (
+	"cmp"
+	"math/rand"
+)
+
+type KLLSketchBuilder[T cmp.Ordered] struct {
+	k      uint
+	seed   int64
+	seeded bool
+}
+
+func NewKLLSketchBuilder[T cmp.Ordered]() *KLLSketchBuilder[T] {
+	return &KLLSketchBuilder[T]{k: DefaultK}
+}
+
+// rank error shrinks ~ 1 / k, see NormalizedRankError
+func (b *KLLSketchBuilder[T]) SetK(k uint) *KLLSketchBuilder[T] {
+	b.k = k
+	return b
+}
+
+// seed of the compaction offsets, same seed and input give the same sketch
+// seeded from time when unset
+func (b *KLLSketchBuilder[T]) SetSeed(seed int64) *KLLSketchBuilder[T] {
+	b.seed = seed
+	b.seeded = true
+	return b
+}
+
+func (b *KLLSketchBuilder[T]) Build() (*KLLSketch[T], error) {
+	seed := b.seed
+	if !b.seeded {
+		seed = rand.Int63()
+	}
+	return NewKLLSketch[T](b.k, seed)
+}
@@ -0,0 +1,125 @@
+package tdigest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// layout: encoding (1 byte) | compression, min, max (float64) | centroids (uvarint) | centroids
+//   - verboseEncoding: mean, weight as float64
+//   - smallEncoding: mean as float64, weight as uvarint (only when every weight is integral)
+const (
+	verboseEncoding byte = 1
+	smallEncoding   byte = 2
+)
+
+const (
+	InvalidEncodingMsg = "invalid t-digest encoding (%v)"
+	TruncatedDataMsg   = "truncated t-digest data"
+)
+
+func (d *TDigest) MarshalBinary() ([]byte, error) {
+	d.compress()
+
+	encoding := smallEncoding
+	for _, c := range d.centroids {
+		if c.Weight != math.Trunc(c.Weight) || c.Weight > math.MaxUint32 {
+			encoding = verboseEncoding
+			break
+		}
+	}
+
+	data := make([]byte, 0, 1+3*8+binary.MaxVarintLen64+len(d.centroids)*16)
+	data = append(data, encoding)
+	data = binary.LittleEndian.AppendUint64(data, math.Float64bits(d.compression))
+	data = binary.LittleEndian.AppendUint64(data, math.Float64bits(d.min))
+	data = binary.LittleEndian.AppendUint64(data, math.Float64bits(d.max))
+	data = binary.AppendUvarint(data, uint64(len(d.centroids)))
+
+	for _, c := range d.centroids {
+		data = binary.LittleEndian.AppendUint64(data, math.Float64bits(c.Mean))
+		if encoding == smallEncoding {
+			data = binary.AppendUvarint(data, uint64(c.Weight))
+		} else {
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(c.Weight))
+		}
+	}
+	return data, nil
+}
+
+func (d *TDigest) UnmarshalBinary(data []byte) error {
+	if len(data) < 1+3*8 {
+		return fmt.Errorf(TruncatedDataMsg)
+	}
+	encoding := data[0]
+	if encoding != verboseEncoding && encoding != smallEncoding {
+		return fmt.Errorf(InvalidEncodingMsg, encoding)
+	}
+	readFloat := func() float64 {
+		v := math.Float64frombits(binary.LittleEndian.Uint64(data))
+		data = data[8:]
+		return v
+	}
+	data = data[1:]
+	compression := readFloat()
+	digest, err := NewTDigest(compression)
+	if err != nil {
+		return err
+	}
+	digest.min = readFloat()
+	digest.max = readFloat()
+
+	n, read := binary.Uvarint(data)
+	if read <= 0 {
+		return fmt.Errorf(TruncatedDataMsg)
+	}
+	data = data[read:]
+	// smallest centroid: mean and a 1-byte uvarint weight
+	entrySize := uint64(9)
+	if encoding == verboseEncoding {
+		entrySize = 16
+	}
+	if n > uint64(len(data))/entrySize {
+		return fmt.Errorf(TruncatedDataMsg)
+	}
+
+	digest.centroids = make([]Centroid, 0, n)
+	for i := uint64(0); i < n; i++ {
+		if len(data) < 8 {
+			return fmt.Errorf(TruncatedDataMsg)
+		}
+		c := Centroid{Mean: readFloat()}
+		if encoding == smallEncoding {
+			weight, read := binary.Uvarint(data)
+			if read <= 0 {
+				return fmt.Errorf(TruncatedDataMsg)
+			}
+			data = data[read:]
+			c.Weight = float64(weight)
+		} else {
+			if len(data) < 8 {
+				return fmt.Errorf(TruncatedDataMsg)
+			}
+			c.Weight = readFloat()
+		}
+		// same checks as Add, and compress relies on means being sorted
+		if math.IsNaN(c.Mean) || math.IsInf(c.Mean, 0) {
+			return fmt.Errorf(InvalidValueMsg, c.Mean)
+		}
+		if !(c.Weight > 0) || math.IsInf(c.Weight, 0) {
+			return fmt.Errorf(InvalidWeightMsg, c.Weight)
+		}
+		if i > 0 && c.Mean < digest.centroids[i-1].Mean {
+			return fmt.Errorf(InvalidEncodingMsg, fmt.Sprintf("unsorted means at centroid %v", i))
+		}
+		digest.centroids = append(digest.centroids, c)
+		digest.totalWeight += c.Weight
+	}
+	if math.IsInf(digest.totalWeight, 0) {
+		return fmt.Errorf(InvalidWeightMsg, digest.totalWeight)
+	}
+
+	*d = *digest
+	return nil
+}
@@ -0,0 +1,202 @@
+package tdigest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// merging t-digest (Dunning & Ertl, 2019)
+// values are clustered into centroids (mean, weight), centroids near q = 0 and q = 1 are kept small
+// by the k1 scale function k(q) = delta / 2pi * asin(2q - 1): a centroid may span at most 1 unit of k
+// new values go to a buffer that is merged into the centroids when full
+type TDigest struct {
+	compression  float64
+	centroids    []Centroid // sorted by mean
+	totalWeight  float64
+	buffer       []Centroid
+	bufferWeight float64
+	min          float64
+	max          float64
+}
+
+type Centroid struct {
+	Mean   float64
+	Weight float64
+}
+
+const (
+	InvalidCompressionMsg = "invalid compression (%v not in [%v, %v])"
+	InvalidWeightMsg      = "invalid weight (%v <= 0)"
+	InvalidValueMsg       = "invalid value (%v)"
+	InvalidQuantileMsg    = "invalid quantile (%v not in [0, 1])"
+)
+
+const (
+	MinCompression = 10
+	// the buffer holds 5 * compression centroids
+	MaxCompression = 100000
+)
+
+func NewTDigest(compression float64) (*TDigest, error) {
+	if !(compression >= MinCompression && compression <= MaxCompression) {
+		return nil, fmt.Errorf(InvalidCompressionMsg, compression, MinCompression, MaxCompression)
+	}
+	d := &TDigest{
+		compression: compression,
+		// buffer of a few times the max centroid count amortizes sorting
+		buffer: make([]Centroid, 0, 5*int(math.Ceil(compression))),
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+	}
+	return d, nil
+}
+
+func (d *TDigest) Compression() float64 { return d.compression }
+
+func (d *TDigest) Count() float64 {
+	return d.totalWeight + d.bufferWeight
+}
+
+func (d *TDigest) Centroids() []Centroid {
+	d.compress()
+	centroids := make([]Centroid, len(d.centroids))
+	copy(centroids, d.centroids)
+	return centroids
+}
+
+func (d *TDigest) Add(value float64, weight float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf(InvalidValueMsg, value)
+	}
+	if !(weight > 0) || math.IsInf(weight, 0) {
+		return fmt.Errorf(InvalidWeightMsg, weight)
+	}
+	d.buffer = append(d.buffer, Centroid{value, weight})
+	d.bufferWeight += weight
+	d.min = math.Min(d.min, value)
+	d.max = math.Max(d.max, value)
+	if len(d.buffer) == cap(d.buffer) {
+		d.compress()
+	}
+	return nil
+}
+
+func (d *TDigest) k(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (d *TDigest) kInverse(k float64) float64 {
+	if k >= d.compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/d.compression) + 1) / 2
+}
+
+func (d *TDigest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := append(d.buffer, d.centroids...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Mean < all[j].Mean })
+	total := d.totalWeight + d.bufferWeight
+
+	merged := make([]Centroid, 0, len(d.centroids)+1)
+	current := all[0]
+	weightSoFar := 0.0
+	weightLimit := total * d.kInverse(d.k(0)+1)
+	for _, next := range all[1:] {
+		if weightSoFar+current.Weight+next.Weight <= weightLimit {
+			current.Weight += next.Weight
+			current.Mean += (next.Mean - current.Mean) * next.Weight / current.Weight
+			continue
+		}
+		merged = append(merged, current)
+		weightSoFar += current.Weight
+		weightLimit = total * d.kInverse(d.k(weightSoFar/total)+1)
+		current = next
+	}
+	merged = append(merged, current)
+
+	d.centroids = merged
+	d.totalWeight = total
+	d.buffer = d.buffer[:0]
+	d.bufferWeight = 0
+}
+
+// centroid means placed at the cumulative weight of their centers, min / max at both ends
+// quantile and cdf interpolate linearly between these points
+func (d *TDigest) points() (weights, values []float64) {
+	d.compress()
+	n := len(d.centroids)
+	weights = make([]float64, 0, n+2)
+	values = make([]float64, 0, n+2)
+	weights = append(weights, 0)
+	values = append(values, d.min)
+	cumulative := 0.0
+	for _, c := range d.centroids {
+		weights = append(weights, cumulative+c.Weight/2)
+		values = append(values, c.Mean)
+		cumulative += c.Weight
+	}
+	weights = append(weights, cumulative)
+	values = append(values, d.max)
+	return weights, values
+}
+
+func (d *TDigest) Quantile(q float64) (float64, error) {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return math.NaN(), fmt.Errorf(InvalidQuantileMsg, q)
+	}
+	if d.Count() == 0 {
+		return math.NaN(), nil
+	}
+	weights, values := d.points()
+	index := q * d.totalWeight
+	i := sort.SearchFloat64s(weights, index)
+	if i == 0 {
+		return values[0], nil
+	}
+	if i >= len(weights) {
+		return values[len(values)-1], nil
+	}
+	return interpolate(index, weights[i-1], weights[i], values[i-1], values[i]), nil
+}
+
+// fraction of weight <= x
+func (d *TDigest) CDF(x float64) float64 {
+	if d.Count() == 0 {
+		return math.NaN()
+	}
+	if x < d.min {
+		return 0
+	}
+	if x >= d.max {
+		return 1
+	}
+	weights, values := d.points()
+	// last point with value <= x, ties take the highest weight
+	i := sort.Search(len(values), func(i int) bool { return values[i] > x })
+	return interpolate(x, values[i-1], values[i], weights[i-1], weights[i]) / d.totalWeight
+}
+
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 == x0 {
+		return y1
+	}
+	return y0 + (x-x0)*(y1-y0)/(x1-x0)
+}
+
+// adds centroids of other digest, compression stays the one of d
+func (d *TDigest) Merge(other *TDigest) {
+	other.compress()
+	for _, c := range other.centroids {
+		d.buffer = append(d.buffer, c)
+		d.bufferWeight += c.Weight
+	}
+	if other.Count() > 0 {
+		d.min = math.Min(d.min, other.min)
+		d.max = math.Max(d.max, other.max)
+	}
+	d.compress()
+}
@@ -0,0 +1,22 @@
+package tdigest
+
+type TDigestBuilder struct {
+	compression float64
+}
+
+func NewTDigestBuilder() *TDigestBuilder {
+	return &TDigestBuilder{
+		// the usual default of t-digest implementations
+		compression: 100,
+	}
+}
+
+// higher compression keeps more centroids, error shrinks roughly as 1 / compression
+func (b *TDigestBuilder) SetCompression(compression float64) *TDigestBuilder {
+	b.compression = compression
+	return b
+}
+
+func (b *TDigestBuilder) Build() (*TDigest, error) {
+	return NewTDigest(b.compression)
+}
@@ -0,0 +1,126 @@
+package sampling
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// priority sampling (Duffield, Lund & Thorup, 2007)
+// item i gets priority w_i / u_i with u_i in (0, 1] hashed from its key, the k highest priorities are kept
+// tau = (k + 1)-th highest priority, sampled item i estimates max(w_i, tau)
+// priorities only depend on key and weight, so samples of disjoint partitions merge exactly
+type PrioritySample[T hasher.HashOutType, V any] struct {
+	k     uint
+	n     uint64
+	items priorityHeap[V] // k + 1 highest priorities, root is tau
+	seed  T
+	h     hasher.HashGenerator[T]
+}
+
+type prioritizedItem[V any] struct {
+	Item[V]
+	priority float64
+}
+
+// min-heap on priority
+type priorityHeap[V any] []prioritizedItem[V]
+
+func (h priorityHeap[V]) Len() int           { return len(h) }
+func (h priorityHeap[V]) Less(i, j int) bool { return h[i].priority < h[j].priority }
+func (h priorityHeap[V]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *priorityHeap[V]) Push(x any)        { *h = append(*h, x.(prioritizedItem[V])) }
+func (h *priorityHeap[V]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func newPrioritySample[T hasher.HashOutType, V any](k uint, seed T, h hasher.HashGenerator[T]) (*PrioritySample[T, V], error) {
+	if k == 0 {
+		return nil, fmt.Errorf(InvalidCapMsg, k)
+	}
+	return &PrioritySample[T, V]{k: k, items: make(priorityHeap[V], 0, k+1), seed: seed, h: h}, nil
+}
+
+func (s *PrioritySample[T, V]) Cap() uint        { return s.k }
+func (s *PrioritySample[T, V]) Count() uint64    { return s.n }
+func (s *PrioritySample[T, V]) HashAttr() string { return s.h.String() }
+
+// threshold priority, 0 while every item is kept
+func (s *PrioritySample[T, V]) Tau() float64 {
+	if uint(len(s.items)) <= s.k {
+		return 0
+	}
+	return s.items[0].priority
+}
+
+func (s *PrioritySample[T, V]) push(item prioritizedItem[V]) {
+	if uint(len(s.items)) <= s.k {
+		heap.Push(&s.items, item)
+	} else if item.priority > s.items[0].priority {
+		s.items[0] = item
+		heap.Fix(&s.items, 0)
+	}
+}
+
+// items of weight 0 are counted but never sampled
+func (s *PrioritySample[T, V]) Add(key string, value V, weight float64) error {
+	if !(weight >= 0) || math.IsInf(weight, 0) {
+		return fmt.Errorf(InvalidWeightMsg, weight)
+	}
+	s.n++
+	if weight == 0 {
+		return nil
+	}
+	u, err := hashUniform(&s.h, key, s.seed)
+	if err != nil {
+		return err
+	}
+	s.push(prioritizedItem[V]{Item[V]{key, value, weight}, weight / u})
+	return nil
+}
+
+// k sampled items with adjusted weights, highest priority first
+func (s *PrioritySample[T, V]) Sample() []SampledItem[V] {
+	sorted := slices.Clone(s.items)
+	slices.SortFunc(sorted, func(a, b prioritizedItem[V]) int {
+		if a.priority > b.priority {
+			return -1
+		}
+		return 1
+	})
+	tau := s.Tau()
+	if tau > 0 {
+		// the (k + 1)-th item only sets tau
+		sorted = sorted[:s.k]
+	}
+	sample := make([]SampledItem[V], len(sorted))
+	for i, item := range sorted {
+		sample[i] = SampledItem[V]{item.Item, math.Max(item.Weight, tau)}
+	}
+	return sample
+}
+
+func (s *PrioritySample[T, V]) SubsetSum(predicate func(Item[V]) bool) SubsetSumEstimate {
+	return subsetSum(s.Sample(), predicate)
+}
+
+// partitions must hold disjoint keys and use the same hash and seed
+func (s *PrioritySample[T, V]) Merge(other *PrioritySample[T, V]) error {
+	if s.k != other.k {
+		return fmt.Errorf(MismatchedCapMsg, s.k, other.k)
+	}
+	if !s.h.Compatible(other.h) || s.seed != other.seed {
+		return fmt.Errorf(MismatchedHashMsg, s.h.String(), other.h.String())
+	}
+	for _, item := range other.items {
+		s.push(item)
+	}
+	s.n += other.n
+	return nil
+}
@@ -0,0 +1,39 @@
+package sampling
+
+import (
+	"math"
+	"math/rand"
+)
+
+// uniform in (0, 1), logs of it stay finite
+func openUniform(rng *rand.Rand) float64 {
+	for {
+		if u := rng.Float64(); u > 0 {
+			return u
+		}
+	}
+}
+
+// Gamma(shape, 1) (Marsaglia & Tsang, 2000), shape >= 1
+func gamma(rng *rand.Rand, shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := openUniform(rng)
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// Beta(a, b) for a, b >= 1
+func beta(rng *rand.Rand, a, b float64) float64 {
+	x := gamma(rng, a)
+	return x / (x + gamma(rng, b))
+}
@@ -0,0 +1,101 @@
+package sampling
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// uniform reservoir sampling, Algorithm L (Li, 1994)
+// instead of drawing a random number per item, jump straight to the next item that enters the reservoir:
+// W is the largest of the k smallest random keys seen so far, the gap to the next key below W is geometric
+type Reservoir[T any] struct {
+	k     uint
+	n     uint64
+	items []T
+	w     float64
+	next  uint64 // 1-based index of the next item that replaces a sample
+	rng   *rand.Rand
+}
+
+const (
+	InvalidCapMsg     = "invalid sample capacity (%v <= 0)"
+	InvalidWeightMsg  = "invalid weight (%v)"
+	MismatchedCapMsg  = "can't merge samples of different capacity (%v != %v)"
+	MismatchedHashMsg = "can't merge samples of different hash or seed (%v != %v)"
+)
+
+func newReservoir[T any](k uint, rng *rand.Rand) (*Reservoir[T], error) {
+	if k == 0 {
+		return nil, fmt.Errorf(InvalidCapMsg, k)
+	}
+	return &Reservoir[T]{k: k, items: make([]T, 0, k), rng: rng}, nil
+}
+
+func (r *Reservoir[T]) Cap() uint     { return r.k }
+func (r *Reservoir[T]) Count() uint64 { return r.n }
+
+func (r *Reservoir[T]) Sample() []T {
+	sample := make([]T, len(r.items))
+	copy(sample, r.items)
+	return sample
+}
+
+func (r *Reservoir[T]) skip() {
+	r.next += uint64(math.Floor(math.Log(openUniform(r.rng))/math.Log1p(-r.w))) + 1
+}
+
+func (r *Reservoir[T]) Add(item T) {
+	r.n++
+	if uint(len(r.items)) < r.k {
+		r.items = append(r.items, item)
+		if uint(len(r.items)) == r.k {
+			r.w = math.Exp(math.Log(openUniform(r.rng)) / float64(r.k))
+			r.next = r.n
+			r.skip()
+		}
+		return
+	}
+	if r.n == r.next {
+		r.items[r.rng.Intn(int(r.k))] = item
+		r.w *= math.Exp(math.Log(openUniform(r.rng)) / float64(r.k))
+		r.skip()
+	}
+}
+
+// r becomes a uniform sample of both streams
+// slots are drawn without replacement from either reservoir in proportion to the
+// items each one still stands for, so a shard that saw more items contributes more
+func (r *Reservoir[T]) Merge(other *Reservoir[T]) error {
+	if r.k != other.k {
+		return fmt.Errorf(MismatchedCapMsg, r.k, other.k)
+	}
+	if other.n == 0 {
+		return nil
+	}
+	pools := [2][]T{r.Sample(), other.Sample()}
+	remaining := [2]uint64{r.n, other.n}
+	merged := make([]T, 0, r.k)
+	for uint(len(merged)) < r.k && remaining[0]+remaining[1] > 0 {
+		from := 1
+		if uint64(r.rng.Int63n(int64(remaining[0]+remaining[1]))) < remaining[0] {
+			from = 0
+		}
+		pool := pools[from]
+		i := r.rng.Intn(len(pool))
+		merged = append(merged, pool[i])
+		pool[i] = pool[len(pool)-1]
+		pools[from] = pool[:len(pool)-1]
+		remaining[from]--
+	}
+
+	r.items = merged
+	r.n += other.n
+	if uint(len(r.items)) == r.k {
+		// W is the k-th smallest of n uniform keys ~ Beta(k, n - k + 1), independent of the sample
+		r.w = beta(r.rng, float64(r.k), float64(r.n-uint64(r.k)+1))
+		r.next = r.n
+		r.skip()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package sampling
+
+import (
+	"math/rand"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type ReservoirBuilder[T any] struct {
+	k   uint
+	src rand.Source
+}
+
+func NewReservoirBuilder[T any]() *ReservoirBuilder[T] {
+	return &ReservoirBuilder[T]{k: 100}
+}
+
+func (b *ReservoirBuilder[T]) SetCap(k uint) *ReservoirBuilder[T] {
+	b.k = k
+	return b
+}
+
+// random source of the sampler, seeded from time when unset
+func (b *ReservoirBuilder[T]) SetRandSource(src rand.Source) *ReservoirBuilder[T] {
+	b.src = src
+	return b
+}
+
+func (b *ReservoirBuilder[T]) rng() *rand.Rand {
+	if b.src == nil {
+		return rand.New(rand.NewSource(rand.Int63()))
+	}
+	return rand.New(b.src)
+}
+
+func (b *ReservoirBuilder[T]) Build() (*Reservoir[T], error) {
+	return newReservoir[T](b.k, b.rng())
+}
+
+func (b *ReservoirBuilder[T]) BuildWeighted() (*WeightedReservoir[T], error) {
+	return newWeightedReservoir[T](b.k, b.rng())
+}
+
+type SubsetSamplerBuilder[T hasher.HashOutType, V any] struct {
+	k       uint
+	seed    T
+	h       hasher.HashGenerator[T]
+	hashErr error
+	src     rand.Source
+}
+
+func NewSubsetSamplerBuilder[T hasher.HashOutType, V any]() *SubsetSamplerBuilder[T, V] {
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &SubsetSamplerBuilder[T, V]{k: 100, h: *defaultHasher}
+}
+
+func (b *SubsetSamplerBuilder[T, V]) SetCap(k uint) *SubsetSamplerBuilder[T, V] {
+	b.k = k
+	return b
+}
+
+// nodes sharing a seed and hash draw the same priority for a key
+func (b *SubsetSamplerBuilder[T, V]) SetSeed(seed T) *SubsetSamplerBuilder[T, V] {
+	b.seed = seed
+	return b
+}
+
+// random source of VarOpt merges, seeded from time when unset
+func (b *SubsetSamplerBuilder[T, V]) SetRandSource(src rand.Source) *SubsetSamplerBuilder[T, V] {
+	b.src = src
+	return b
+}
+
+func (b *SubsetSamplerBuilder[T, V]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *SubsetSamplerBuilder[T, V] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *SubsetSamplerBuilder[T, V]) BuildPriority() (*PrioritySample[T, V], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newPrioritySample[T, V](b.k, b.seed, b.h)
+}
+
+func (b *SubsetSamplerBuilder[T, V]) BuildVarOpt() (*VarOptSample[T, V], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	rng := rand.New(rand.NewSource(rand.Int63()))
+	if b.src != nil {
+		rng = rand.New(b.src)
+	}
+	return newVarOptSample[T, V](b.k, b.seed, b.h, rng)
+}
@@ -0,0 +1,56 @@
+package sampling
+
+import (
+	"math"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// weighted item of a subset-sum sample, key identifies it across nodes
+type Item[V any] struct {
+	Key    string
+	Value  V
+	Weight float64
+}
+
+// sampled item with its Horvitz-Thompson weight, weight / inclusion probability
+type SampledItem[V any] struct {
+	Item[V]
+	AdjustedWeight float64
+}
+
+type SubsetSumEstimate struct {
+	Value    float64
+	Variance float64
+}
+
+func (e SubsetSumEstimate) StdError() float64 {
+	return math.Sqrt(e.Variance)
+}
+
+// unbiased sum of sampled items matching predicate
+// an item kept with probability w / tau has adjusted weight tau and variance estimate tau (tau - w)
+// (Duffield, Lund & Thorup, 2007), items kept for sure contribute no variance
+func subsetSum[V any](items []SampledItem[V], predicate func(Item[V]) bool) SubsetSumEstimate {
+	estimate := SubsetSumEstimate{}
+	for _, item := range items {
+		if !predicate(item.Item) {
+			continue
+		}
+		estimate.Value += item.AdjustedWeight
+		estimate.Variance += item.AdjustedWeight * (item.AdjustedWeight - item.Weight)
+	}
+	return estimate
+}
+
+// uniform in (0, 1] from the item key, the same key gets the same draw on every node
+func hashUniform[T hasher.HashOutType](h *hasher.HashGenerator[T], key string, seed T) (float64, error) {
+	hashes, err := h.GenerateHash([]byte(key), seed, math.MaxUint, 1)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(^T(0)) == math.MaxUint32 {
+		return (float64(hashes[0]) + 1) / (1 << 32), nil
+	}
+	return (float64(uint64(hashes[0])>>11) + 1) / (1 << 53), nil
+}
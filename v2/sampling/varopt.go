@@ -0,0 +1,134 @@
+package sampling
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// VarOpt_k (Cohen, Duffield, Kaplan, Lund & Thorup, 2011)
+// keeps k items, heavy items (w >= tau) with their own weight and light ones with adjusted weight tau
+// where sum min(1, w_i / tau) = k; on overflow one light item is dropped with probability 1 - a_i / tau
+// minimizes the variance of subset sums over all k-samples, light items have non-positive covariance
+// the drop decision on Add is drawn from the hash of the incoming key so a node replays the same sample
+// for the same arrivals, unlike priority sampling it depends on arrival order
+// merged items already survived a draw from their key hash, Merge draws from rng instead
+type VarOptSample[T hasher.HashOutType, V any] struct {
+	k     uint
+	n     uint64
+	items []SampledItem[V]
+	tau   float64
+	seed  T
+	h     hasher.HashGenerator[T]
+	rng   *rand.Rand
+}
+
+func newVarOptSample[T hasher.HashOutType, V any](k uint, seed T, h hasher.HashGenerator[T], rng *rand.Rand) (*VarOptSample[T, V], error) {
+	if k == 0 {
+		return nil, fmt.Errorf(InvalidCapMsg, k)
+	}
+	return &VarOptSample[T, V]{k: k, items: make([]SampledItem[V], 0, k+1), seed: seed, h: h, rng: rng}, nil
+}
+
+func (s *VarOptSample[T, V]) Cap() uint        { return s.k }
+func (s *VarOptSample[T, V]) Count() uint64    { return s.n }
+func (s *VarOptSample[T, V]) Tau() float64     { return s.tau }
+func (s *VarOptSample[T, V]) HashAttr() string { return s.h.String() }
+
+// items of weight 0 are counted but never sampled
+func (s *VarOptSample[T, V]) Add(key string, value V, weight float64) error {
+	if !(weight >= 0) || math.IsInf(weight, 0) {
+		return fmt.Errorf(InvalidWeightMsg, weight)
+	}
+	s.n++
+	if weight == 0 {
+		return nil
+	}
+	u, err := hashUniform(&s.h, key, s.seed+1)
+	if err != nil {
+		return err
+	}
+	s.insert(SampledItem[V]{Item[V]{key, value, weight}, weight}, u)
+	return nil
+}
+
+// item.AdjustedWeight is the weight it enters with (its own, or an adjusted one when merging)
+// u in (0, 1] picks the light item to drop on overflow
+func (s *VarOptSample[T, V]) insert(item SampledItem[V], u float64) {
+	s.items = append(s.items, item)
+	if uint(len(s.items)) <= s.k {
+		return
+	}
+
+	// k + 1 candidates, find tau with sum min(1, a_i / tau) = k:
+	// the j lightest are light iff a_j <= tau = (sum of j lightest) / (j - 1) <= a_(j+1)
+	slices.SortFunc(s.items, func(a, b SampledItem[V]) int {
+		switch {
+		case a.AdjustedWeight < b.AdjustedWeight:
+			return -1
+		case a.AdjustedWeight > b.AdjustedWeight:
+			return 1
+		}
+		return 0
+	})
+	light := 0
+	tau, prefix := 0.0, 0.0
+	for j := 1; j <= len(s.items); j++ {
+		prefix += s.items[j-1].AdjustedWeight
+		if j < 2 {
+			continue
+		}
+		t := prefix / float64(j-1)
+		if s.items[j-1].AdjustedWeight <= t && (j == len(s.items) || t <= s.items[j].AdjustedWeight) {
+			light, tau = j, t
+			break
+		}
+	}
+	if light == 0 {
+		// rounding, every candidate is light
+		light, tau = len(s.items), prefix/float64(len(s.items)-1)
+	}
+
+	// drop probabilities of light items sum to j - (j - 1) = 1
+	drop := light - 1
+	for i := 0; i < light; i++ {
+		u -= 1 - s.items[i].AdjustedWeight/tau
+		if u <= 0 {
+			drop = i
+			break
+		}
+	}
+	s.items = slices.Delete(s.items, drop, drop+1)
+	for i := 0; i < light-1; i++ {
+		s.items[i].AdjustedWeight = tau
+	}
+	s.tau = tau
+}
+
+func (s *VarOptSample[T, V]) Sample() []SampledItem[V] {
+	return slices.Clone(s.items)
+}
+
+func (s *VarOptSample[T, V]) SubsetSum(predicate func(Item[V]) bool) SubsetSumEstimate {
+	return subsetSum(s.items, predicate)
+}
+
+// feeding other's items with their adjusted weights gives a VarOpt sample of the union
+// (Cohen et al., section 5), original weights are kept for variance estimates
+func (s *VarOptSample[T, V]) Merge(other *VarOptSample[T, V]) error {
+	if s.k != other.k {
+		return fmt.Errorf(MismatchedCapMsg, s.k, other.k)
+	}
+	if !s.h.Compatible(other.h) || s.seed != other.seed {
+		return fmt.Errorf(MismatchedHashMsg, s.h.String(), other.h.String())
+	}
+	for _, item := range other.items {
+		// reusing the key hash would drop survivors less often than their weights say
+		s.insert(item, openUniform(s.rng))
+	}
+	s.n += other.n
+	return nil
+}
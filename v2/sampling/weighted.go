@@ -0,0 +1,132 @@
+package sampling
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// weighted reservoir sampling without replacement, A-ExpJ (Efraimidis & Spirakis, 2006)
+// item i gets key u^(1 / w_i), the k largest keys form the sample (A-Res)
+// exponential jumps skip items until the accumulated weight reaches log(u) / log(T), T = min key
+// keys are kept as log(u) / w_i to stay finite for large weights
+type WeightedReservoir[T any] struct {
+	k           uint
+	n           uint64
+	totalWeight float64
+	items       keyHeap[T]
+	jump        float64 // weight left to skip before the next insertion
+	rng         *rand.Rand
+}
+
+type WeightedItem[T any] struct {
+	Item   T
+	Weight float64
+}
+
+type keyedItem[T any] struct {
+	WeightedItem[T]
+	logKey float64
+}
+
+// min-heap on key, root is the threshold T
+type keyHeap[T any] []keyedItem[T]
+
+func (h keyHeap[T]) Len() int           { return len(h) }
+func (h keyHeap[T]) Less(i, j int) bool { return h[i].logKey < h[j].logKey }
+func (h keyHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap[T]) Push(x any)        { *h = append(*h, x.(keyedItem[T])) }
+func (h *keyHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func newWeightedReservoir[T any](k uint, rng *rand.Rand) (*WeightedReservoir[T], error) {
+	if k == 0 {
+		return nil, fmt.Errorf(InvalidCapMsg, k)
+	}
+	return &WeightedReservoir[T]{k: k, items: make(keyHeap[T], 0, k), rng: rng}, nil
+}
+
+func (r *WeightedReservoir[T]) Cap() uint            { return r.k }
+func (r *WeightedReservoir[T]) Count() uint64        { return r.n }
+func (r *WeightedReservoir[T]) TotalWeight() float64 { return r.totalWeight }
+
+// sampled items, heaviest key first
+func (r *WeightedReservoir[T]) Sample() []WeightedItem[T] {
+	sorted := slices.Clone(r.items)
+	slices.SortFunc(sorted, func(a, b keyedItem[T]) int {
+		if a.logKey > b.logKey {
+			return -1
+		}
+		return 1
+	})
+	sample := make([]WeightedItem[T], len(sorted))
+	for i, item := range sorted {
+		sample[i] = item.WeightedItem
+	}
+	return sample
+}
+
+func (r *WeightedReservoir[T]) resetJump() {
+	r.jump = math.Log(openUniform(r.rng)) / r.items[0].logKey
+}
+
+// items of weight 0 are counted but never sampled
+func (r *WeightedReservoir[T]) Add(item T, weight float64) error {
+	if !(weight >= 0) || math.IsInf(weight, 0) {
+		return fmt.Errorf(InvalidWeightMsg, weight)
+	}
+	r.n++
+	if weight == 0 {
+		return nil
+	}
+	r.totalWeight += weight
+
+	if uint(len(r.items)) < r.k {
+		heap.Push(&r.items, keyedItem[T]{WeightedItem[T]{item, weight}, math.Log(openUniform(r.rng)) / weight})
+		if uint(len(r.items)) == r.k {
+			r.resetJump()
+		}
+		return nil
+	}
+
+	r.jump -= weight
+	if r.jump > 0 {
+		return nil
+	}
+	// new key is drawn from (T^w, 1) so it beats the current threshold
+	tw := math.Exp(weight * r.items[0].logKey)
+	u := tw + (1-tw)*openUniform(r.rng)
+	r.items[0] = keyedItem[T]{WeightedItem[T]{item, weight}, math.Log(u) / weight}
+	heap.Fix(&r.items, 0)
+	r.resetJump()
+	return nil
+}
+
+// keys are independent per item, so the k largest keys of both reservoirs
+// are exactly the sample of the combined stream
+func (r *WeightedReservoir[T]) Merge(other *WeightedReservoir[T]) error {
+	if r.k != other.k {
+		return fmt.Errorf(MismatchedCapMsg, r.k, other.k)
+	}
+	for _, item := range other.items {
+		if uint(len(r.items)) < r.k {
+			heap.Push(&r.items, item)
+		} else if item.logKey > r.items[0].logKey {
+			r.items[0] = item
+			heap.Fix(&r.items, 0)
+		}
+	}
+	r.n += other.n
+	r.totalWeight += other.totalWeight
+	if uint(len(r.items)) == r.k {
+		// jumps are memoryless, a fresh one is as good as the old one
+		r.resetJump()
+	}
+	return nil
+}
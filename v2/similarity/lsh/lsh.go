@@ -0,0 +1,160 @@
+package lsh
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nnurry/probabilistics/v2/similarity/minhash"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// MinHash LSH banding (Leskovec, Rajaraman & Ullman, Mining of Massive Datasets ch. 3.4)
+// signature is cut into b bands of r rows, documents sharing any whole band become candidates
+// a pair with Jaccard s collides with probability 1 - (1 - s^r)^b, an S-curve with threshold ~ (1/b)^(1/r)
+type LSHIndex[T hasher.HashOutType] struct {
+	bands   uint
+	rows    uint
+	buckets []map[uint64]map[string]struct{} // band -> band key -> ids
+	keys    map[string][]uint64              // id -> band keys, for Remove
+	// MinHash setup of the 1st inserted signature, k = 0 until then
+	k        uint
+	mode     minhash.Mode
+	seed     T
+	hashAttr string
+}
+
+const (
+	InvalidBandingMsg   = "invalid banding (bands = %v, rows = %v)"
+	ShortSignatureMsg   = "signature too short (%v < bands * rows = %v)"
+	DuplicateIdMsg      = "id %q already indexed"
+	InvalidThresholdMsg = "invalid threshold (%v not in (0, 1))"
+)
+
+func newLSHIndex[T hasher.HashOutType](bands, rows uint) (*LSHIndex[T], error) {
+	if bands == 0 || rows == 0 {
+		return nil, fmt.Errorf(InvalidBandingMsg, bands, rows)
+	}
+	buckets := make([]map[uint64]map[string]struct{}, bands)
+	for i := range buckets {
+		buckets[i] = map[uint64]map[string]struct{}{}
+	}
+	return &LSHIndex[T]{bands: bands, rows: rows, buckets: buckets, keys: map[string][]uint64{}}, nil
+}
+
+func (x *LSHIndex[T]) Bands() uint { return x.bands }
+func (x *LSHIndex[T]) Rows() uint  { return x.rows }
+func (x *LSHIndex[T]) Len() uint   { return uint(len(x.keys)) }
+
+// Jaccard at which the collision probability curve is steepest
+func (x *LSHIndex[T]) Threshold() float64 {
+	return Threshold(x.bands, x.rows)
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// bands only collide between signatures of equally configured MinHashers
+func (x *LSHIndex[T]) compatible(sig *minhash.Signature[T]) error {
+	switch {
+	case x.k == 0:
+		return nil
+	case sig.NumHashes() != x.k:
+		return fmt.Errorf(minhash.MismatchedSigMsg, fmt.Sprintf("k: %v != %v", sig.NumHashes(), x.k))
+	case sig.Mode() != x.mode:
+		return fmt.Errorf(minhash.MismatchedSigMsg, fmt.Sprintf("mode: %v != %v", sig.Mode(), x.mode))
+	case sig.Seed() != x.seed:
+		return fmt.Errorf(minhash.MismatchedSigMsg, fmt.Sprintf("seed: %v != %v", sig.Seed(), x.seed))
+	case sig.HashAttr() != x.hashAttr:
+		return fmt.Errorf(minhash.MismatchedSigMsg, fmt.Sprintf("hash: %v != %v", sig.HashAttr(), x.hashAttr))
+	}
+	return nil
+}
+
+// one key per band, folded from the band's slot values
+func (x *LSHIndex[T]) bandKeys(sig *minhash.Signature[T]) ([]uint64, error) {
+	if err := x.compatible(sig); err != nil {
+		return nil, err
+	}
+	if sig.NumHashes() < x.bands*x.rows {
+		return nil, fmt.Errorf(ShortSignatureMsg, sig.NumHashes(), x.bands*x.rows)
+	}
+	values := sig.Values()
+	keys := make([]uint64, x.bands)
+	for band := uint(0); band < x.bands; band++ {
+		key := uint64(band)
+		for _, v := range values[band*x.rows : (band+1)*x.rows] {
+			key = splitmix64(key ^ uint64(v))
+		}
+		keys[band] = key
+	}
+	return keys, nil
+}
+
+func (x *LSHIndex[T]) Insert(id string, sig *minhash.Signature[T]) error {
+	if _, ok := x.keys[id]; ok {
+		return fmt.Errorf(DuplicateIdMsg, id)
+	}
+	keys, err := x.bandKeys(sig)
+	if err != nil {
+		return err
+	}
+	for band, key := range keys {
+		bucket, ok := x.buckets[band][key]
+		if !ok {
+			bucket = map[string]struct{}{}
+			x.buckets[band][key] = bucket
+		}
+		bucket[id] = struct{}{}
+	}
+	x.keys[id] = keys
+	if x.k == 0 {
+		x.k, x.mode, x.seed, x.hashAttr = sig.NumHashes(), sig.Mode(), sig.Seed(), sig.HashAttr()
+	}
+	return nil
+}
+
+// ids sharing at least 1 band with sig, sorted
+// candidates still need an exact (or signature) Jaccard check
+func (x *LSHIndex[T]) Query(sig *minhash.Signature[T]) ([]string, error) {
+	keys, err := x.bandKeys(sig)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	candidates := []string{}
+	for band, key := range keys {
+		for id := range x.buckets[band][key] {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				candidates = append(candidates, id)
+			}
+		}
+	}
+	slices.Sort(candidates)
+	return candidates, nil
+}
+
+func (x *LSHIndex[T]) Contains(id string) bool {
+	_, ok := x.keys[id]
+	return ok
+}
+
+func (x *LSHIndex[T]) Remove(id string) bool {
+	keys, ok := x.keys[id]
+	if !ok {
+		return false
+	}
+	for band, key := range keys {
+		bucket := x.buckets[band][key]
+		delete(bucket, id)
+		if len(bucket) == 0 {
+			delete(x.buckets[band], key)
+		}
+	}
+	delete(x.keys, id)
+	return true
+}
@@ -0,0 +1,40 @@
+package lsh
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type LSHIndexBuilder[T hasher.HashOutType] struct {
+	bands uint
+	rows  uint
+}
+
+func NewLSHIndexBuilder[T hasher.HashOutType]() *LSHIndexBuilder[T] {
+	// 128 hashes, threshold 0.5 with equal weights
+	defaultBands, defaultRows, _ := LSHEstimateParams(0.5, 128, 0.5, 0.5)
+	return &LSHIndexBuilder[T]{bands: defaultBands, rows: defaultRows}
+}
+
+func (b *LSHIndexBuilder[T]) SetBands(bands uint) *LSHIndexBuilder[T] {
+	b.bands = bands
+	return b
+}
+
+func (b *LSHIndexBuilder[T]) SetRows(rows uint) *LSHIndexBuilder[T] {
+	b.rows = rows
+	return b
+}
+
+// pick bands and rows via LSHEstimateParams, invalid input keeps the current banding
+func (b *LSHIndexBuilder[T]) SetThreshold(threshold float64, numHashes uint, fpWeight, fnWeight float64) *LSHIndexBuilder[T] {
+	bands, rows, err := LSHEstimateParams(threshold, numHashes, fpWeight, fnWeight)
+	if err != nil {
+		return b
+	}
+	b.bands, b.rows = bands, rows
+	return b
+}
+
+func (b *LSHIndexBuilder[T]) Build() (*LSHIndex[T], error) {
+	return newLSHIndex[T](b.bands, b.rows)
+}
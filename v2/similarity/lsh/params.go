@@ -0,0 +1,52 @@
+package lsh
+
+import (
+	"fmt"
+	"math"
+)
+
+// probability that a pair with Jaccard s shares at least 1 band
+func CollisionProbability(s float64, bands, rows uint) float64 {
+	return 1 - math.Pow(1-math.Pow(s, float64(rows)), float64(bands))
+}
+
+func Threshold(bands, rows uint) float64 {
+	return math.Pow(1/float64(bands), 1/float64(rows))
+}
+
+// simpson's rule, n even
+func integrate(f func(float64) float64, a, b float64, n int) float64 {
+	step := (b - a) / float64(n)
+	sum := f(a) + f(b)
+	for i := 1; i < n; i++ {
+		weight := 2.0
+		if i%2 == 1 {
+			weight = 4
+		}
+		sum += weight * f(a+float64(i)*step)
+	}
+	return sum * step / 3
+}
+
+// (b, r) with b * r <= numHashes minimizing fpWeight * FP + fnWeight * FN where
+// FP = area under the collision curve below threshold, FN = area above it missed by the curve
+// (same objective as datasketch's MinHashLSH)
+func LSHEstimateParams(threshold float64, numHashes uint, fpWeight, fnWeight float64) (bands, rows uint, err error) {
+	if !(threshold > 0 && threshold < 1) {
+		return 0, 0, fmt.Errorf(InvalidThresholdMsg, threshold)
+	}
+	if numHashes == 0 {
+		return 0, 0, fmt.Errorf(InvalidBandingMsg, 0, 0)
+	}
+	minError := math.Inf(1)
+	for b := uint(1); b <= numHashes; b++ {
+		for r := uint(1); b*r <= numHashes; r++ {
+			fp := integrate(func(s float64) float64 { return CollisionProbability(s, b, r) }, 0, threshold, 100)
+			fn := integrate(func(s float64) float64 { return 1 - CollisionProbability(s, b, r) }, threshold, 1, 100)
+			if e := fpWeight*fp + fnWeight*fn; e < minError {
+				minError, bands, rows = e, b, r
+			}
+		}
+	}
+	return bands, rows, nil
+}
@@ -0,0 +1,114 @@
+package minhash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// layout: version, mode, value width in bytes (1 byte each) | k, seed (uvarint)
+// | hash attribute (uvarint length + string) | k raw slot values, little endian
+const encodingVersion byte = 1
+
+const (
+	InvalidEncodingMsg = "invalid signature encoding (%v)"
+	TruncatedDataMsg   = "truncated signature data"
+)
+
+func valueWidth[T hasher.HashOutType]() byte {
+	if uint64(^T(0)) == math.MaxUint32 {
+		return 4
+	}
+	return 8
+}
+
+func (s *Signature[T]) MarshalBinary() ([]byte, error) {
+	width := valueWidth[T]()
+	data := make([]byte, 0, 3+2*binary.MaxVarintLen64+len(s.hashAttr)+int(s.k)*int(width))
+	data = append(data, encodingVersion, byte(s.mode), width)
+	data = binary.AppendUvarint(data, uint64(s.k))
+	data = binary.AppendUvarint(data, uint64(s.seed))
+	data = binary.AppendUvarint(data, uint64(len(s.hashAttr)))
+	data = append(data, s.hashAttr...)
+	for _, v := range s.values {
+		if width == 4 {
+			data = binary.LittleEndian.AppendUint32(data, uint32(v))
+		} else {
+			data = binary.LittleEndian.AppendUint64(data, uint64(v))
+		}
+	}
+	return data, nil
+}
+
+// decoded signatures can be compared and merged but not added to
+func (s *Signature[T]) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 {
+		return fmt.Errorf(TruncatedDataMsg)
+	}
+	if data[0] != encodingVersion {
+		return fmt.Errorf(InvalidEncodingMsg, fmt.Sprintf("version %v", data[0]))
+	}
+	mode := Mode(data[1])
+	if mode != KPermutation && mode != OnePermutation {
+		return fmt.Errorf(InvalidModeMsg, mode)
+	}
+	width := data[2]
+	if width != valueWidth[T]() {
+		return fmt.Errorf(InvalidEncodingMsg, fmt.Sprintf("value width %v != %v", width, valueWidth[T]()))
+	}
+	data = data[3:]
+
+	readUvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, fmt.Errorf(TruncatedDataMsg)
+		}
+		data = data[n:]
+		return v, nil
+	}
+	k, err := readUvarint()
+	if err != nil {
+		return err
+	}
+	if k == 0 {
+		return fmt.Errorf(InvalidNumHashesMsg, k)
+	}
+	seed, err := readUvarint()
+	if err != nil {
+		return err
+	}
+	attrLen, err := readUvarint()
+	if err != nil {
+		return err
+	}
+	if uint64(len(data)) < attrLen {
+		return fmt.Errorf(TruncatedDataMsg)
+	}
+	// k is checked before the multiplication, a huge k would wrap around
+	rest := uint64(len(data)) - attrLen
+	if k > rest/uint64(width) || rest != k*uint64(width) {
+		return fmt.Errorf(TruncatedDataMsg)
+	}
+	hashAttr := string(data[:attrLen])
+	data = data[attrLen:]
+
+	values := make([]T, k)
+	for i := range values {
+		if width == 4 {
+			values[i] = T(binary.LittleEndian.Uint32(data[4*i:]))
+		} else {
+			values[i] = T(binary.LittleEndian.Uint64(data[8*i:]))
+		}
+	}
+
+	*s = Signature[T]{
+		k:        uint(k),
+		mode:     mode,
+		seed:     T(seed),
+		hashAttr: hashAttr,
+		values:   values,
+	}
+	return nil
+}
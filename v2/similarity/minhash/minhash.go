@@ -0,0 +1,233 @@
+package minhash
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// MinHash (Broder, 1997): Pr[min h(A) = min h(B)] = |A n B| / |A u B|
+// so the fraction of matching slots over k signatures estimates Jaccard similarity
+//   - KPermutation: k hashes per shingle, slot i keeps the min of hash i (O(k) per shingle)
+//   - OnePermutation: 1 hash per shingle split into k bins by h mod k, slot keeps the min of h / k
+//     in its bin (Li et al., 2012), empty bins are filled by optimal densification (Shrivastava, 2017)
+type Mode uint8
+
+const (
+	KPermutation Mode = iota
+	OnePermutation
+)
+
+func (m Mode) String() string {
+	switch m {
+	case KPermutation:
+		return "k-permutation"
+	case OnePermutation:
+		return "one-permutation"
+	}
+	return fmt.Sprintf("mode(%d)", uint8(m))
+}
+
+const (
+	InvalidNumHashesMsg  = "invalid number of hashes (%v <= 0)"
+	InvalidModeMsg       = "invalid mode (%v)"
+	MismatchedSigMsg     = "mismatched signatures (%v)"
+	DetachedSignatureMsg = "signature has no hash generator (decoded signatures are read-only)"
+)
+
+// produces signatures that are comparable with each other
+type MinHasher[T hasher.HashOutType] struct {
+	k    uint
+	mode Mode
+	seed T
+	h    hasher.HashGenerator[T]
+}
+
+type Signature[T hasher.HashOutType] struct {
+	k        uint
+	mode     Mode
+	seed     T
+	hashAttr string
+	values   []T // raw mins, empty = max value of T
+	h        *hasher.HashGenerator[T]
+}
+
+func newMinHasher[T hasher.HashOutType](k uint, mode Mode, seed T, h hasher.HashGenerator[T]) (*MinHasher[T], error) {
+	if k == 0 {
+		return nil, fmt.Errorf(InvalidNumHashesMsg, k)
+	}
+	if mode != KPermutation && mode != OnePermutation {
+		return nil, fmt.Errorf(InvalidModeMsg, mode)
+	}
+	return &MinHasher[T]{k: k, mode: mode, seed: seed, h: h}, nil
+}
+
+func (m *MinHasher[T]) NumHashes() uint  { return m.k }
+func (m *MinHasher[T]) Mode() Mode       { return m.mode }
+func (m *MinHasher[T]) HashAttr() string { return m.h.String() }
+
+// standard error of the Jaccard estimate at similarity j is sqrt(j(1 - j) / k)
+func (m *MinHasher[T]) StdError(jaccard float64) float64 {
+	return math.Sqrt(jaccard * (1 - jaccard) / float64(m.k))
+}
+
+func (m *MinHasher[T]) NewSignature() *Signature[T] {
+	values := make([]T, m.k)
+	for i := range values {
+		values[i] = ^T(0)
+	}
+	return &Signature[T]{
+		k:        m.k,
+		mode:     m.mode,
+		seed:     m.seed,
+		hashAttr: m.h.String(),
+		values:   values,
+		h:        &m.h,
+	}
+}
+
+// signature of a whole shingle set
+func (m *MinHasher[T]) Signature(shingles [][]byte) (*Signature[T], error) {
+	sig := m.NewSignature()
+	for _, shingle := range shingles {
+		if err := sig.Add(shingle); err != nil {
+			return nil, err
+		}
+	}
+	return sig, nil
+}
+
+func (s *Signature[T]) NumHashes() uint  { return s.k }
+func (s *Signature[T]) Mode() Mode       { return s.mode }
+func (s *Signature[T]) Seed() T          { return s.seed }
+func (s *Signature[T]) HashAttr() string { return s.hashAttr }
+
+func (s *Signature[T]) Add(shingle []byte) error {
+	if s.h == nil {
+		return fmt.Errorf(DetachedSignatureMsg)
+	}
+	if s.mode == KPermutation {
+		hashes, err := s.h.GenerateHash(shingle, s.seed, math.MaxUint, s.k)
+		if err != nil {
+			return err
+		}
+		for i, hash := range hashes {
+			s.values[i] = min(s.values[i], permute(hash, uint64(i)))
+		}
+		return nil
+	}
+
+	hashes, err := s.h.GenerateHash(shingle, s.seed, math.MaxUint, 1)
+	if err != nil {
+		return err
+	}
+	bin := hashes[0] % T(s.k)
+	s.values[bin] = min(s.values[bin], hashes[0]/T(s.k))
+	return nil
+}
+
+// true if no shingle was added
+func (s *Signature[T]) IsEmpty() bool {
+	for _, v := range s.values {
+		if v != ^T(0) {
+			return false
+		}
+	}
+	return true
+}
+
+// signature slots, empty one-permutation bins are densified
+func (s *Signature[T]) Values() []T {
+	values := make([]T, s.k)
+	copy(values, s.values)
+	if s.mode == OnePermutation {
+		s.densify(values)
+	}
+	return values
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// derived hashes of seeded families are not independent enough for min-wise hashing
+// (seeds seed, seed + 1, ... give correlated mins and bias Jaccard low), so each slot
+// gets its own bijection on top of the family hash
+func permute[T hasher.HashOutType](hash T, slot uint64) T {
+	return T(splitmix64(uint64(hash) ^ splitmix64(slot)))
+}
+
+// optimal densification: empty bin i borrows from the first non-empty bin in its own
+// pseudo-random probe sequence, sequences only depend on (seed, i, attempt)
+// so two signatures borrow from the same bins and stay comparable
+func (s *Signature[T]) densify(values []T) {
+	empty := ^T(0)
+	nonEmpty := 0
+	for _, v := range s.values {
+		if v != empty {
+			nonEmpty++
+		}
+	}
+	if nonEmpty == 0 || nonEmpty == len(values) {
+		return
+	}
+	for i := range values {
+		if s.values[i] != empty {
+			continue
+		}
+		for attempt := uint64(1); ; attempt++ {
+			j := splitmix64(uint64(s.seed)^uint64(i)<<32^attempt) % uint64(s.k)
+			if s.values[j] != empty {
+				values[i] = s.values[j]
+				break
+			}
+		}
+	}
+}
+
+func (s *Signature[T]) compatible(other *Signature[T]) error {
+	switch {
+	case s.k != other.k:
+		return fmt.Errorf(MismatchedSigMsg, fmt.Sprintf("k: %v != %v", s.k, other.k))
+	case s.mode != other.mode:
+		return fmt.Errorf(MismatchedSigMsg, fmt.Sprintf("mode: %v != %v", s.mode, other.mode))
+	case s.seed != other.seed:
+		return fmt.Errorf(MismatchedSigMsg, fmt.Sprintf("seed: %v != %v", s.seed, other.seed))
+	case s.hashAttr != other.hashAttr:
+		return fmt.Errorf(MismatchedSigMsg, fmt.Sprintf("hash: %v != %v", s.hashAttr, other.hashAttr))
+	}
+	return nil
+}
+
+// fraction of equal slots, 0 if either signature is empty
+func (s *Signature[T]) Jaccard(other *Signature[T]) (float64, error) {
+	if err := s.compatible(other); err != nil {
+		return 0, err
+	}
+	if s.IsEmpty() || other.IsEmpty() {
+		return 0, nil
+	}
+	a, b := s.Values(), other.Values()
+	matches := 0
+	for i := range a {
+		if a[i] == b[i] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(s.k), nil
+}
+
+// signature of the union of both shingle sets, slot-wise min
+func (s *Signature[T]) Merge(other *Signature[T]) error {
+	if err := s.compatible(other); err != nil {
+		return err
+	}
+	for i, v := range other.values {
+		s.values[i] = min(s.values[i], v)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package minhash
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type MinHasherBuilder[T hasher.HashOutType] struct {
+	k       uint
+	mode    Mode
+	seed    T
+	h       hasher.HashGenerator[T]
+	hashErr error
+}
+
+func NewMinHasherBuilder[T hasher.HashOutType]() *MinHasherBuilder[T] {
+	defaultHasher, _ := hasher.NewHashGenerator[T]("murmur3Hash128Default", 64, 128, "standard")
+	return &MinHasherBuilder[T]{
+		k:    128,
+		mode: KPermutation,
+		h:    *defaultHasher,
+	}
+}
+
+// signature length, std error of Jaccard is at most 1 / (2 sqrt(k))
+func (b *MinHasherBuilder[T]) SetNumHashes(k uint) *MinHasherBuilder[T] {
+	b.k = k
+	return b
+}
+
+func (b *MinHasherBuilder[T]) SetMode(mode Mode) *MinHasherBuilder[T] {
+	b.mode = mode
+	return b
+}
+
+func (b *MinHasherBuilder[T]) SetSeed(seed T) *MinHasherBuilder[T] {
+	b.seed = seed
+	return b
+}
+
+func (b *MinHasherBuilder[T]) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *MinHasherBuilder[T] {
+	hashGenerator, err := hasher.NewHashGenerator[T](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *MinHasherBuilder[T]) Build() (*MinHasher[T], error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newMinHasher(b.k, b.mode, b.seed, b.h)
+}
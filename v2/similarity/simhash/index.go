@@ -0,0 +1,198 @@
+package simhash
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Hamming-distance index (Manku, Jain & Das Sarma, 2007)
+// 64 bits are split into n blocks, 2 fingerprints within distance k differ in at most k blocks
+// so they agree exactly on some choice of n - k blocks: one table per choice (C(n, k) tables)
+// each table stores fingerprints permuted so the chosen blocks come first, sorted
+// and a query only scans the range sharing those leading bits
+type Index struct {
+	distance uint
+	blocks   []block
+	tables   []table
+	ids      map[string]uint64
+}
+
+type block struct {
+	offset uint
+	width  uint
+}
+
+type table struct {
+	order   []int // block order, key blocks first
+	keyBits uint
+	entries []entry
+	sorted  bool
+}
+
+type entry struct {
+	permuted    uint64
+	fingerprint uint64
+	id          string
+}
+
+type Match struct {
+	Id          string
+	Fingerprint uint64
+	Distance    uint
+}
+
+const (
+	InvalidDistanceMsg = "invalid distance (%v not in [0, 32])"
+	InvalidBlocksMsg   = "invalid blocks (%v not in (distance = %v, 64])"
+	TooManyTablesMsg   = "too many tables (C(%v, %v) = %v > %v)"
+	DuplicateIdMsg     = "id %q already indexed"
+)
+
+const (
+	MaxDistance = 32
+	MaxTables   = 1024
+)
+
+func binomial(n, k uint) uint {
+	c := uint(1)
+	for i := uint(1); i <= k; i++ {
+		c = c * (n - k + i) / i
+	}
+	return c
+}
+
+func newIndex(distance, blockCount uint) (*Index, error) {
+	if distance > MaxDistance {
+		return nil, fmt.Errorf(InvalidDistanceMsg, distance)
+	}
+	if blockCount <= distance || blockCount > 64 {
+		return nil, fmt.Errorf(InvalidBlocksMsg, blockCount, distance)
+	}
+	if tables := binomial(blockCount, distance); tables > MaxTables {
+		return nil, fmt.Errorf(TooManyTablesMsg, blockCount, distance, tables, MaxTables)
+	}
+
+	blocks := make([]block, blockCount)
+	offset := uint(0)
+	for i := range blocks {
+		width := 64 / blockCount
+		if uint(i) < 64%blockCount {
+			width++
+		}
+		blocks[i] = block{offset, width}
+		offset += width
+	}
+
+	x := &Index{distance: distance, blocks: blocks, ids: map[string]uint64{}}
+	// every (n - k)-subset of blocks is the key of 1 table
+	keySize := int(blockCount - distance)
+	var choose func(start int, chosen []int)
+	choose = func(start int, chosen []int) {
+		if len(chosen) == keySize {
+			order := slices.Clone(chosen)
+			keyBits := uint(0)
+			for _, b := range chosen {
+				keyBits += blocks[b].width
+			}
+			for b := range blocks {
+				if !slices.Contains(chosen, b) {
+					order = append(order, b)
+				}
+			}
+			x.tables = append(x.tables, table{order: order, keyBits: keyBits, sorted: true})
+			return
+		}
+		for b := start; b < len(blocks); b++ {
+			choose(b+1, append(chosen, b))
+		}
+	}
+	choose(0, make([]int, 0, keySize))
+	return x, nil
+}
+
+func (x *Index) Distance() uint { return x.distance }
+func (x *Index) Tables() uint   { return uint(len(x.tables)) }
+func (x *Index) Len() uint      { return uint(len(x.ids)) }
+
+// move blocks of fingerprint to the top in table order
+func (x *Index) permute(t *table, fingerprint uint64) uint64 {
+	permuted := uint64(0)
+	for _, b := range t.order {
+		width := x.blocks[b].width
+		bits := fingerprint >> x.blocks[b].offset & (1<<width - 1)
+		permuted = permuted<<width | bits
+	}
+	return permuted
+}
+
+func (x *Index) Insert(id string, fingerprint uint64) error {
+	if _, ok := x.ids[id]; ok {
+		return fmt.Errorf(DuplicateIdMsg, id)
+	}
+	for i := range x.tables {
+		t := &x.tables[i]
+		t.entries = append(t.entries, entry{x.permute(t, fingerprint), fingerprint, id})
+		t.sorted = false
+	}
+	x.ids[id] = fingerprint
+	return nil
+}
+
+func (x *Index) Remove(id string) bool {
+	if _, ok := x.ids[id]; !ok {
+		return false
+	}
+	for i := range x.tables {
+		t := &x.tables[i]
+		t.entries = slices.DeleteFunc(t.entries, func(e entry) bool { return e.id == id })
+	}
+	delete(x.ids, id)
+	return true
+}
+
+// every indexed fingerprint within the index distance, closest first
+func (x *Index) Query(fingerprint uint64) []Match {
+	seen := map[string]struct{}{}
+	matches := []Match{}
+	for i := range x.tables {
+		t := &x.tables[i]
+		if !t.sorted {
+			slices.SortFunc(t.entries, func(a, b entry) int {
+				switch {
+				case a.permuted < b.permuted:
+					return -1
+				case a.permuted > b.permuted:
+					return 1
+				}
+				return 0
+			})
+			t.sorted = true
+		}
+		keyMask := ^uint64(0) << (64 - t.keyBits)
+		key := x.permute(t, fingerprint) & keyMask
+		start := sort.Search(len(t.entries), func(j int) bool { return t.entries[j].permuted >= key })
+		for _, e := range t.entries[start:] {
+			if e.permuted&keyMask != key {
+				break
+			}
+			if _, ok := seen[e.id]; ok {
+				continue
+			}
+			if d := HammingDistance(e.fingerprint, fingerprint); d <= x.distance {
+				seen[e.id] = struct{}{}
+				matches = append(matches, Match{e.id, e.fingerprint, d})
+			}
+		}
+	}
+	slices.SortFunc(matches, func(a, b Match) int {
+		if a.Distance != b.Distance {
+			return int(a.Distance) - int(b.Distance)
+		}
+		if a.Id < b.Id {
+			return -1
+		}
+		return 1
+	})
+	return matches
+}
@@ -0,0 +1,31 @@
+package simhash
+
+type IndexBuilder struct {
+	distance uint
+	blocks   uint
+}
+
+func NewIndexBuilder() *IndexBuilder {
+	// Manku et al. setting for web pages: k = 3, blocks = 0 picks distance + 2
+	return &IndexBuilder{distance: 3}
+}
+
+// max Hamming distance of a match
+func (b *IndexBuilder) SetDistance(distance uint) *IndexBuilder {
+	b.distance = distance
+	return b
+}
+
+// more blocks -> longer keys and fewer candidates per lookup, but C(blocks, distance) tables
+func (b *IndexBuilder) SetBlocks(blocks uint) *IndexBuilder {
+	b.blocks = blocks
+	return b
+}
+
+func (b *IndexBuilder) Build() (*Index, error) {
+	blocks := b.blocks
+	if blocks == 0 {
+		blocks = min(64, b.distance+2)
+	}
+	return newIndex(b.distance, blocks)
+}
@@ -0,0 +1,77 @@
+package simhash
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// SimHash (Charikar, 2002)
+// every feature hash votes +w / -w on each of the 64 bits, fingerprint bit i = sign of the vote sum
+// Pr[bit differs] = angle(u, v) / pi for the weighted feature vectors, so Hamming distance tracks cosine
+type SimHasher struct {
+	seed uint64
+	h    hasher.HashGenerator[uint64]
+}
+
+type Feature struct {
+	Token  []byte
+	Weight float64
+}
+
+const (
+	InvalidWeightMsg = "invalid weight for token %q (%v)"
+)
+
+func newSimHasher(seed uint64, h hasher.HashGenerator[uint64]) *SimHasher {
+	return &SimHasher{seed: seed, h: h}
+}
+
+func (s *SimHasher) HashAttr() string { return s.h.String() }
+
+// features of weight 1
+func Tokens(tokens ...string) []Feature {
+	features := make([]Feature, len(tokens))
+	for i, token := range tokens {
+		features[i] = Feature{[]byte(token), 1}
+	}
+	return features
+}
+
+func (s *SimHasher) Fingerprint(features []Feature) (uint64, error) {
+	var votes [64]float64
+	for _, feature := range features {
+		if math.IsNaN(feature.Weight) || math.IsInf(feature.Weight, 0) {
+			return 0, fmt.Errorf(InvalidWeightMsg, feature.Token, feature.Weight)
+		}
+		hashes, err := s.h.GenerateHash(feature.Token, s.seed, math.MaxUint, 1)
+		if err != nil {
+			return 0, err
+		}
+		for i := range votes {
+			if hashes[0]>>i&1 == 1 {
+				votes[i] += feature.Weight
+			} else {
+				votes[i] -= feature.Weight
+			}
+		}
+	}
+	fingerprint := uint64(0)
+	for i, vote := range votes {
+		if vote > 0 {
+			fingerprint |= 1 << i
+		}
+	}
+	return fingerprint, nil
+}
+
+func HammingDistance(a, b uint64) uint {
+	return uint(bits.OnesCount64(a ^ b))
+}
+
+// cosine similarity implied by Hamming distance, cos(pi * d / 64)
+func EstimateCosine(a, b uint64) float64 {
+	return math.Cos(math.Pi * float64(HammingDistance(a, b)) / 64)
+}
@@ -0,0 +1,39 @@
+package simhash
+
+import (
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type SimHasherBuilder struct {
+	seed    uint64
+	h       hasher.HashGenerator[uint64]
+	hashErr error
+}
+
+func NewSimHasherBuilder() *SimHasherBuilder {
+	defaultHasher, _ := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "standard")
+	return &SimHasherBuilder{h: *defaultHasher}
+}
+
+func (b *SimHasherBuilder) SetSeed(seed uint64) *SimHasherBuilder {
+	b.seed = seed
+	return b
+}
+
+// any 64-bit family, wider outputs only use their first 64 bits
+func (b *SimHasherBuilder) SetHashGenerator(hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...hasher.HashOption) *SimHasherBuilder {
+	hashGenerator, err := hasher.NewHashGenerator[uint64](hashFamily, platformBit, outputBit, generateMethod, opts...)
+	b.hashErr = err
+	if err != nil {
+		return b
+	}
+	b.h = *hashGenerator
+	return b
+}
+
+func (b *SimHasherBuilder) Build() (*SimHasher, error) {
+	if b.hashErr != nil {
+		return nil, b.hashErr
+	}
+	return newSimHasher(b.seed, b.h), nil
+}
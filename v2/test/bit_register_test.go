@@ -47,3 +47,22 @@ func TestBitRegisterBasic(t *testing.T) {
 	fmt.Println(r.Write(9999, 1))
 	fmt.Println(r.Write(9999, 2))
 }
+
+func TestBitRegisterClear(t *testing.T) {
+	r, _ := register.NewRegister(200, 1)
+	for i := uint(0); i < 200; i++ {
+		r.Write(i, 1)
+	}
+	// clearing a bit leaves the rest of its word alone
+	r.Write(70, 0)
+	r.Decrement(3)
+	for i := uint(0); i < 200; i++ {
+		expected := uint(1)
+		if i == 70 || i == 3 {
+			expected = 0
+		}
+		if v, _ := r.Read(i); v != expected {
+			t.Fatalf("bit %d = %d, expected %d", i, v, expected)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+var testBloomAllocHelperAttrs = []hasher.HashAttribute{
+	{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128},
+	{HashFamily: "murmur3Hash256Bnb", PlatformBit: 64, OutputBit: 256},
+	{HashFamily: "xxh3Hash64", PlatformBit: 64, OutputBit: 64},
+	{HashFamily: "xxh3Hash128", PlatformBit: 64, OutputBit: 128},
+}
+
+func testBloomAllocHelperKeys(n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key-%d", i))
+	}
+	return keys
+}
+
+func testBloomAllocHelperClassic(m uint, attr hasher.HashAttribute, method string) *bloomfilter.ClassicBF[uint64] {
+	r, _ := register.NewRegister(m, 1)
+	return bloomfilter.NewClassicBFBuilder[uint64]().
+		SetCap(m).
+		SetHashNum(7).
+		SetRegister(r.(*register.BitRegister)).
+		SetHashGenerator(attr.HashFamily, attr.PlatformBit, attr.OutputBit, method).
+		Build()
+}
+
+func testBloomAllocHelperCounting(m uint, attr hasher.HashAttribute, method string) *bloomfilter.CountingBF[uint64] {
+	bitR, _ := register.NewRegister(m, 1)
+	countR, _ := register.NewRegister(m, 4)
+	return bloomfilter.NewCountingBFBuilder[uint64]().
+		SetCap(m).
+		SetHashNum(7).
+		SetBitRegister(bitR.(*register.BitRegister)).
+		SetCountRegister(countR).
+		SetHashGenerator(attr.HashFamily, attr.PlatformBit, attr.OutputBit, method).
+		Build()
+}
+
+func TestBloomContainsAllocs(t *testing.T) {
+	if testRaceEnabled {
+		t.Skip("allocations aren't stable under -race")
+	}
+	keys := testBloomAllocHelperKeys(1000)
+	for _, attr := range testBloomAllocHelperAttrs {
+		for _, method := range hasher.GenerateMethods() {
+			classic := testBloomAllocHelperClassic(1<<16, attr, method)
+			counting := testBloomAllocHelperCounting(1<<16, attr, method)
+			for _, key := range keys[:500] {
+				classic.Add(key)
+				counting.Add(key)
+			}
+
+			i := 0
+			allocs := testing.AllocsPerRun(1000, func() {
+				classic.Contains(keys[i%len(keys)])
+				counting.Contains(keys[i%len(keys)])
+				i++
+			})
+			if allocs != 0 {
+				t.Fatalf("%s: %v allocs per Contains", classic.HashAttr(), allocs)
+			}
+			allocs = testing.AllocsPerRun(1000, func() {
+				classic.Add(keys[i%len(keys)])
+				counting.Add(keys[i%len(keys)])
+				counting.Remove(keys[i%len(keys)])
+				i++
+			})
+			if allocs != 0 {
+				t.Fatalf("%s: %v allocs per Add / Remove", classic.HashAttr(), allocs)
+			}
+		}
+	}
+}
+
+// readers share nothing, run with -race
+func TestBloomConcurrentContains(t *testing.T) {
+	keys := testBloomAllocHelperKeys(2000)
+	classic := testBloomAllocHelperClassic(1<<16, testBloomAllocHelperAttrs[0], "standard")
+	counting := testBloomAllocHelperCounting(1<<16, testBloomAllocHelperAttrs[0], "standard")
+	scalable, _ := bloomfilter.NewScalableBFBuilder[uint64]().SetElems(500).Build()
+	for _, key := range keys[:1000] {
+		classic.Add(key)
+		counting.Add(key)
+		scalable.Add(key)
+	}
+	expected := make([]bool, len(keys))
+	for i, key := range keys {
+		expected[i] = classic.Contains(key)
+	}
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 8)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < 5*len(keys); n++ {
+				i := (n*7 + w*131) % len(keys)
+				if classic.Contains(keys[i]) != expected[i] {
+					errs <- fmt.Errorf("classic: %s changed membership", keys[i])
+					return
+				}
+				if i < 1000 && (!counting.Contains(keys[i]) || !scalable.Contains(keys[i])) {
+					errs <- fmt.Errorf("%s: false negative", keys[i])
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestBloomGenerateHashInto(t *testing.T) {
+	for _, attr := range testBloomAllocHelperAttrs {
+		for _, method := range hasher.GenerateMethods() {
+			g, err := hasher.NewHashGenerator[uint64](attr.HashFamily, attr.PlatformBit, attr.OutputBit, method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf []uint64
+			for _, times := range []uint{1, 3, 7, 2} {
+				expected, _ := g.GenerateHash([]byte("sample"), 3, 1<<20, times)
+				buf, err = g.GenerateHashInto(buf, []byte("sample"), 3, 1<<20, times)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fmt.Sprint(buf) != fmt.Sprint(expected) {
+					t.Fatalf("%s, times = %d: %v != %v", g, times, buf, expected)
+				}
+			}
+		}
+	}
+}
+
+func testBloomAllocHelperBenchmark(b *testing.B, attr hasher.HashAttribute, method string, contains bool) {
+	keys := testBloomAllocHelperKeys(1 << 12)
+	f := testBloomAllocHelperClassic(1<<20, attr, method)
+	for _, key := range keys[:len(keys)/2] {
+		f.Add(key)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if contains {
+			f.Contains(keys[i%len(keys)])
+		} else {
+			f.Add(keys[i%len(keys)])
+		}
+	}
+}
+
+func BenchmarkClassicBFContains(b *testing.B) {
+	for _, attr := range testBloomAllocHelperAttrs {
+		for _, method := range []string{"standard", "kirsch-mitzenmacher", "triple-hashing"} {
+			b.Run(attr.HashFamily+"/"+method, func(b *testing.B) {
+				testBloomAllocHelperBenchmark(b, attr, method, true)
+			})
+		}
+	}
+}
+
+func BenchmarkClassicBFAdd(b *testing.B) {
+	for _, attr := range testBloomAllocHelperAttrs {
+		for _, method := range []string{"standard", "kirsch-mitzenmacher", "triple-hashing"} {
+			b.Run(attr.HashFamily+"/"+method, func(b *testing.B) {
+				testBloomAllocHelperBenchmark(b, attr, method, false)
+			})
+		}
+	}
+}
+
+func BenchmarkCountingBFContains(b *testing.B) {
+	keys := testBloomAllocHelperKeys(1 << 12)
+	f := testBloomAllocHelperCounting(1<<20, testBloomAllocHelperAttrs[0], "standard")
+	for _, key := range keys[:len(keys)/2] {
+		f.Add(key)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Contains(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkScalableBFContains(b *testing.B) {
+	keys := testBloomAllocHelperKeys(1 << 12)
+	f, _ := bloomfilter.NewScalableBFBuilder[uint64]().Build()
+	for _, key := range keys[:len(keys)/2] {
+		f.Add(key)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Contains(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkGenerateHash(b *testing.B) {
+	g, _ := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "standard")
+	data := []byte("sample")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.GenerateHash(data, 0, 1<<20, 7)
+	}
+}
+
+func BenchmarkGenerateHashInto(b *testing.B) {
+	g, _ := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "standard")
+	data := []byte("sample")
+	var buf []uint64
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ = g.GenerateHashInto(buf, data, 0, 1<<20, 7)
+	}
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/frequency/countmin"
+)
+
+func TestCountMinCreate(t *testing.T) {
+	s, err := countmin.NewCountMinSketchBuilder[uint64]().Build()
+	if err != nil {
+		t.Fatal("can't create count-min sketch:", err)
+	}
+	fmt.Printf(
+		"count-min sketch: width=%d, depth=%d, eps=%.4f, confidence=%.4f, bytes=%d, hash=[%s]\n",
+		s.Width(), s.Depth(), s.Epsilon(), s.Confidence(), s.ByteSize(), s.HashAttr(),
+	)
+}
+
+// zipf-like stream: item i appears (n / (i + 1)) times
+func testCountMinHelperStream(n int) map[string]uint {
+	counts := map[string]uint{}
+	for i := 0; i < n; i++ {
+		counts[fmt.Sprintf("data %b", i)] = uint(n / (i + 1))
+	}
+	return counts
+}
+
+func TestCountMinBasic(t *testing.T) {
+	width, depth := countmin.CountMinEstimateParams(0.001, 0.001)
+	s, _ := countmin.NewCountMinSketchBuilder[uint64]().SetWidth(width).SetDepth(depth).Build()
+
+	counts := testCountMinHelperStream(20000)
+	for item, count := range counts {
+		s.Add([]byte(item), count)
+	}
+
+	violations := 0
+	for item, count := range counts {
+		estimate := s.Estimate([]byte(item))
+		if estimate < count {
+			t.Fatalf("estimate %d of %q below true count %d", estimate, item, count)
+		}
+		if float64(estimate-count) > s.ErrorBound() {
+			violations++
+		}
+	}
+	fmt.Printf("N = %d, error bound = %.1f, violations = %d / %d\n", s.TotalCount(), s.ErrorBound(), violations, len(counts))
+	if float64(violations) > 0.001*float64(len(counts))+1 {
+		t.Fatalf("too many estimates over error bound: %d", violations)
+	}
+}
+
+func TestCountMinMerge(t *testing.T) {
+	builder := countmin.NewCountMinSketchBuilder[uint64]().SetWidth(2000).SetDepth(5)
+	a, _ := builder.Build()
+	b, _ := builder.Build()
+	whole, _ := builder.Build()
+
+	for i := 0; i < 5000; i++ {
+		item := []byte(fmt.Sprintf("data %b", i%700))
+		if i%2 == 0 {
+			a.Add(item, 1)
+		} else {
+			b.Add(item, 1)
+		}
+		whole.Add(item, 1)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+	if a.TotalCount() != whole.TotalCount() {
+		t.Fatalf("merged total %d != %d", a.TotalCount(), whole.TotalCount())
+	}
+	for i := 0; i < 700; i++ {
+		item := []byte(fmt.Sprintf("data %b", i))
+		if a.Estimate(item) != whole.Estimate(item) {
+			t.Fatalf("merged estimate of %q = %d != %d", item, a.Estimate(item), whole.Estimate(item))
+		}
+	}
+
+	c, _ := builder.SetWidth(1000).Build()
+	if err := a.Merge(c); err == nil {
+		t.Fatal("expected mismatched sketch error")
+	}
+}
+
+func TestCountMinSaturation(t *testing.T) {
+	s, _ := countmin.NewCountMinSketchBuilder[uint64]().SetWidth(100).SetDepth(3).SetCounterBits(4).Build()
+	for i := 0; i < 20; i++ {
+		if err := s.Add([]byte("hot"), 1); err != nil {
+			t.Fatal("saturated add failed:", err)
+		}
+	}
+	if s.Estimate([]byte("hot")) != 15 {
+		t.Fatalf("expected saturated estimate 15, got %d", s.Estimate([]byte("hot")))
+	}
+}
+
+func TestCountMinVariants(t *testing.T) {
+	counts := testCountMinHelperStream(20000)
+	variants := []struct {
+		name      string
+		update    countmin.UpdateMode
+		estimator countmin.Estimator
+	}{
+		{"count-min", countmin.StandardUpdate, countmin.MinEstimator},
+		{"conservative update", countmin.ConservativeUpdate, countmin.MinEstimator},
+		{"count-mean-min", countmin.StandardUpdate, countmin.CountMeanMinEstimator},
+	}
+
+	tailErrors := []float64{}
+	for _, variant := range variants {
+		s, _ := countmin.NewCountMinSketchBuilder[uint64]().
+			SetWidth(1000).
+			SetDepth(5).
+			SetUpdateMode(variant.update).
+			SetEstimator(variant.estimator).
+			Build()
+		for item, count := range counts {
+			s.Add([]byte(item), count)
+		}
+
+		// long tail: items seen at most 2 times
+		tailError, tailN := 0.0, 0
+		for item, count := range counts {
+			estimate := s.Estimate([]byte(item))
+			if variant.estimator == countmin.MinEstimator && estimate < count {
+				t.Fatalf("%s: estimate %d of %q below true count %d", variant.name, estimate, item, count)
+			}
+			if count <= 2 {
+				tailError += math.Abs(float64(estimate) - float64(count))
+				tailN++
+			}
+		}
+		tailErrors = append(tailErrors, tailError/float64(tailN))
+		fmt.Printf("%s: mean absolute error on long tail = %.2f\n", variant.name, tailError/float64(tailN))
+	}
+
+	if tailErrors[1] >= tailErrors[0] || tailErrors[2] >= tailErrors[0] {
+		t.Fatalf("variants should beat plain count-min on long tail: %v", tailErrors)
+	}
+}
+
+func TestCountMinVariantsSaturation(t *testing.T) {
+	for _, estimator := range []countmin.Estimator{countmin.MinEstimator, countmin.CountMeanMinEstimator} {
+		s, _ := countmin.NewCountMinSketchBuilder[uint64]().
+			SetWidth(100).
+			SetDepth(3).
+			SetCounterBits(4).
+			SetUpdateMode(countmin.ConservativeUpdate).
+			SetEstimator(estimator).
+			Build()
+		for i := 0; i < 20; i++ {
+			if err := s.Add([]byte("hot"), 3); err != nil {
+				t.Fatal("saturated add failed:", err)
+			}
+			s.Add([]byte(fmt.Sprintf("cold %d", i)), 1)
+		}
+		if s.Estimate([]byte("hot")) != 15 {
+			t.Fatalf("expected saturated estimate 15, got %d", s.Estimate([]byte("hot")))
+		}
+		if s.Estimate([]byte("cold 3")) > 2 {
+			t.Fatalf("cold item estimate %d polluted by saturated counters", s.Estimate([]byte("cold 3")))
+		}
+	}
+}
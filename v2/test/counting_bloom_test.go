@@ -32,8 +32,8 @@ func testCountingBloomHelperBasic(
 		SetCountRegister(countR).
 		SetHashGenerator(
 			hashFuncAttr.HashFamily,
-			hashFuncAttr.OutputBit,
 			hashFuncAttr.PlatformBit,
+			hashFuncAttr.OutputBit,
 			testHashGenerateMethod,
 		)
 	bf := builder.Build()
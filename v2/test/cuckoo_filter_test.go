@@ -0,0 +1,101 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/cuckoofilter"
+)
+
+func TestCuckooFilterCreate(t *testing.T) {
+	cf, err := cuckoofilter.NewCuckooFilterBuilder[uint64]().Build()
+	if err != nil {
+		t.Fatal("can't create cuckoo filter:", err)
+	}
+	fmt.Printf(
+		"cuckoo filter: buckets=%d, bucket size=%d, fingerprint bits=%d, bytes=%d, hash=[%s]\n",
+		cf.Buckets(), cf.BucketSize(), cf.FingerprintBits(), cf.ByteSize(), cf.HashAttr(),
+	)
+}
+
+func TestCuckooFilterBasic(t *testing.T) {
+	testN := uint(10000)
+	buckets, fpBits := cuckoofilter.CuckooFilterEstimateParams(0.01, testN, 4)
+
+	cf, err := cuckoofilter.NewCuckooFilterBuilder[uint64]().
+		SetBuckets(buckets).
+		SetBucketSize(4).
+		SetFingerprintBits(fpBits).
+		Build()
+	if err != nil {
+		t.Fatal("can't create cuckoo filter:", err)
+	}
+
+	for i := uint(0); i < testN; i++ {
+		if err := cf.Add([]byte(fmt.Sprintf("data %b", i))); err != nil {
+			t.Fatalf("can't add element %d: %v", i, err)
+		}
+	}
+	if cf.Count() != testN {
+		t.Fatalf("expected count %d, got %d", testN, cf.Count())
+	}
+	for i := uint(0); i < testN; i++ {
+		if !cf.Contains([]byte(fmt.Sprintf("data %b", i))) {
+			t.Fatalf("false negative for element %d", i)
+		}
+	}
+
+	fp := 0
+	for i := 0; i < 100000; i++ {
+		if cf.Contains([]byte(fmt.Sprintf("unadded %b", i))) {
+			fp++
+		}
+	}
+	fpr := float64(fp) / 100000
+	fmt.Printf("load factor = %.2f %%, fpr = %.4f\n", cf.LoadFactor()*100, fpr)
+	if fpr > 0.02 {
+		t.Fatalf("fpr %.4f too far above 0.01", fpr)
+	}
+
+	for i := uint(0); i < testN; i += 2 {
+		if !cf.Remove([]byte(fmt.Sprintf("data %b", i))) {
+			t.Fatalf("can't remove element %d", i)
+		}
+	}
+	for i := uint(1); i < testN; i += 2 {
+		if !cf.Contains([]byte(fmt.Sprintf("data %b", i))) {
+			t.Fatalf("false negative for element %d after removals", i)
+		}
+	}
+	if cf.Count() != testN/2 {
+		t.Fatalf("expected count %d, got %d", testN/2, cf.Count())
+	}
+}
+
+func TestCuckooFilterFull(t *testing.T) {
+	cf, _ := cuckoofilter.NewCuckooFilterBuilder[uint64]().
+		SetBuckets(16).
+		SetBucketSize(2).
+		SetFingerprintBits(12).
+		SetMaxKicks(50).
+		Build()
+
+	added := []uint{}
+	var err error
+	for i := uint(0); i < 1000; i++ {
+		if err = cf.Add([]byte(fmt.Sprintf("data %b", i))); err != nil {
+			break
+		}
+		added = append(added, i)
+	}
+	if err == nil {
+		t.Fatal("expected filter full error")
+	}
+	fmt.Println("error:", err, "load factor:", cf.LoadFactor())
+	// failed insert must not evict anything already added
+	for _, i := range added {
+		if !cf.Contains([]byte(fmt.Sprintf("data %b", i))) {
+			t.Fatalf("element %d lost after failed insert", i)
+		}
+	}
+}
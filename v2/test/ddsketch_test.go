@@ -0,0 +1,117 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/quantile/ddsketch"
+)
+
+func testDDSketchHelperExact(sorted []float64, q float64) float64 {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func TestDDSketchBasic(t *testing.T) {
+	alpha := 0.01
+	sketch, err := ddsketch.NewDDSketchBuilder().SetRelativeAccuracy(alpha).Build()
+	if err != nil {
+		t.Fatal("can't create ddsketch:", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 100000)
+	for i := range values {
+		// heavy-tailed latency in ms, with a few negative clock skews and zeros
+		values[i] = math.Exp(2*rng.NormFloat64()) * 10
+		switch i % 100 {
+		case 0:
+			values[i] = -values[i]
+		case 1:
+			values[i] = 0
+		}
+		sketch.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0, 0.001, 0.005, 0.01, 0.015, 0.1, 0.5, 0.9, 0.99, 0.999, 1} {
+		estimate, _ := sketch.Quantile(q)
+		exact := testDDSketchHelperExact(values, q)
+		relErr := math.Abs(estimate-exact) / math.Abs(exact)
+		if exact == 0 {
+			relErr = math.Abs(estimate)
+		}
+		fmt.Printf("q = %.3f: estimate = %.4f, exact = %.4f, relative error = %.5f\n", q, estimate, exact, relErr)
+		if relErr > alpha+1e-9 {
+			t.Fatalf("q = %.3f: relative error %.5f exceeds %.5f", q, relErr, alpha)
+		}
+	}
+	if sketch.Count() != uint64(len(values)) {
+		t.Fatalf("count %d != %d", sketch.Count(), len(values))
+	}
+	fmt.Printf("byte size = %d, collapsed = %v\n", sketch.ByteSize(), sketch.IsCollapsed())
+
+	if _, err := sketch.Quantile(2); err == nil {
+		t.Fatal("expected invalid quantile error")
+	}
+	if err := sketch.Add(math.NaN()); err == nil {
+		t.Fatal("expected invalid value error")
+	}
+	if _, err := ddsketch.NewDDSketchBuilder().SetRelativeAccuracy(1).Build(); err == nil {
+		t.Fatal("expected invalid relative accuracy error")
+	}
+}
+
+func TestDDSketchMergeCollapse(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	whole, _ := ddsketch.NewDDSketchBuilder().Build()
+	merged, _ := ddsketch.NewDDSketchBuilder().Build()
+	for shard := 0; shard < 8; shard++ {
+		s, _ := ddsketch.NewDDSketchBuilder().Build()
+		for i := 0; i < 10000; i++ {
+			v := rng.ExpFloat64() * math.Pow(10, float64(shard))
+			s.Add(v)
+			whole.Add(v)
+		}
+		if err := merged.Merge(s); err != nil {
+			t.Fatal("can't merge:", err)
+		}
+	}
+	// lossless: merged buckets equal the buckets of a single sketch
+	for _, q := range []float64{0.01, 0.25, 0.5, 0.75, 0.99} {
+		a, _ := merged.Quantile(q)
+		b, _ := whole.Quantile(q)
+		if a != b {
+			t.Fatalf("q = %.2f: merged %v != direct %v", q, a, b)
+		}
+	}
+
+	other, _ := ddsketch.NewDDSketchBuilder().SetRelativeAccuracy(0.02).Build()
+	if err := merged.Merge(other); err == nil {
+		t.Fatal("expected mismatched sketch error")
+	}
+
+	// few buckets and small counters: low values collapse, high quantiles stay accurate
+	small, _ := ddsketch.NewDDSketchBuilder().SetMaxBuckets(100).SetCounterBits(16).Build()
+	values := make([]float64, 50000)
+	for i := range values {
+		values[i] = math.Pow(10, 6*rng.Float64())
+		small.Add(values[i])
+	}
+	sort.Float64s(values)
+	if !small.IsCollapsed() {
+		t.Fatal("expected collapsed sketch")
+	}
+	for _, q := range []float64{0.9, 0.99} {
+		estimate, _ := small.Quantile(q)
+		exact := testDDSketchHelperExact(values, q)
+		fmt.Printf("collapsed q = %.2f: estimate = %.2f, exact = %.2f\n", q, estimate, exact)
+		if math.Abs(estimate-exact)/exact > 0.01+1e-9 {
+			t.Fatalf("q = %.2f: relative error too large after collapse", q)
+		}
+	}
+	if low, _ := small.Quantile(0.01); low < testDDSketchHelperExact(values, 0.01) {
+		t.Fatalf("collapsed low quantile %v should be over-estimated", low)
+	}
+}
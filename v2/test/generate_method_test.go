@@ -0,0 +1,184 @@
+package test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/cardinality/hyperloglog"
+	"github.com/nnurry/probabilistics/v2/frequency/countmin"
+	"github.com/nnurry/probabilistics/v2/frequency/spacesaving"
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+	"github.com/nnurry/probabilistics/v2/membership/cuckoofilter"
+	"github.com/nnurry/probabilistics/v2/membership/quotientfilter"
+	"github.com/nnurry/probabilistics/v2/membership/xorfilter"
+	"github.com/nnurry/probabilistics/v2/sampling"
+	"github.com/nnurry/probabilistics/v2/similarity/minhash"
+	"github.com/nnurry/probabilistics/v2/similarity/simhash"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// the base hashes a double hashing scheme starts from, 2 calls for narrow hash functions
+func testGenerateMethodHelperBase(t *testing.T, family string, outputBit uint, n int) []uint64 {
+	hf, err := hasher.NewHashFunction[uint64](family, 64, outputBit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := []uint64{}
+	for seed := uint64(0); len(base) < n; seed++ {
+		hashes, _ := hf([]byte("sample"), seed)
+		base = append(base, hashes...)
+	}
+	return base[:n]
+}
+
+// Build error of every builder for 1 SetHashGenerator call
+func testGenerateMethodHelperBuilders(family string, platformBit uint, outputBit uint, method string, opts ...hasher.HashOption) map[string]error {
+	errs := map[string]error{}
+	_, errs["classic"] = bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).TryBuild()
+	_, errs["counting"] = bloomfilter.NewCountingBFBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).TryBuild()
+	_, errs["scalable"] = bloomfilter.NewScalableBFBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["cuckoo"] = cuckoofilter.NewCuckooFilterBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["quotient"] = quotientfilter.NewQuotientFilterBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["xor"] = xorfilter.NewStaticFilterBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).BuildXor([][]byte{[]byte("item")})
+	_, errs["binary fuse"] = xorfilter.NewStaticFilterBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).BuildBinaryFuse([][]byte{[]byte("item")})
+	_, errs["hyperloglog"] = hyperloglog.NewHyperLogLogBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["count-min"] = countmin.NewCountMinSketchBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["space-saving"] = spacesaving.NewSpaceSavingBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["priority"] = sampling.NewSubsetSamplerBuilder[uint64, int]().SetHashGenerator(family, platformBit, outputBit, method, opts...).BuildPriority()
+	_, errs["varopt"] = sampling.NewSubsetSamplerBuilder[uint64, int]().SetHashGenerator(family, platformBit, outputBit, method, opts...).BuildVarOpt()
+	_, errs["minhash"] = minhash.NewMinHasherBuilder[uint64]().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	_, errs["simhash"] = simhash.NewSimHasherBuilder().SetHashGenerator(family, platformBit, outputBit, method, opts...).Build()
+	return errs
+}
+
+func TestGenerateMethodUnknown(t *testing.T) {
+	if _, err := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "kirsh-mitzenmacher"); err == nil {
+		t.Fatal("mistyped generate method accepted")
+	}
+	if _, err := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, ""); err == nil {
+		t.Fatal("empty generate method accepted")
+	}
+	for _, method := range []string{"standard", "extended-double-hashing", "kirsch-mitzenmacher-legacy", "kirsch-mitzenmacher", "triple-hashing", "independent-seeds"} {
+		if !slices.Contains(hasher.GenerateMethods(), method) {
+			t.Fatal("missing generate method", method)
+		}
+		if _, err := hasher.NewHashGenerator[uint32]("halfSipHash24", 32, 32, method, hasher.WithKey([16]byte{})); err != nil {
+			t.Fatal("generate method unavailable for uint32:", method, err)
+		}
+	}
+
+	// builders report it instead of keeping their default generator
+	for name, err := range testGenerateMethodHelperBuilders("murmur3Hash128Default", 64, 128, "kirsh-mitzenmacher") {
+		if err == nil {
+			t.Fatal(name, "builder accepted a mistyped generate method")
+		}
+	}
+	for name, err := range testGenerateMethodHelperBuilders("murmur3Hash128Default", 64, 128, "triple-hashing") {
+		if err != nil {
+			t.Fatal(name, "builder rejected triple hashing:", err)
+		}
+	}
+	if bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator("murmur3Hash128Default", 64, 128, "").Build() != nil {
+		t.Fatal("Build returned a filter without its generate method")
+	}
+}
+
+func TestGenerateMethodKirschMitzenmacher(t *testing.T) {
+	const m = 1000003
+	for _, attr := range []hasher.HashAttribute{
+		{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128},
+		{HashFamily: "xxh3Hash64", PlatformBit: 64, OutputBit: 64},
+	} {
+		g, err := hasher.NewHashGenerator[uint64](attr.HashFamily, attr.PlatformBit, attr.OutputBit, "kirsch-mitzenmacher")
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes, err := g.GenerateHash([]byte("sample"), 0, m, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base := testGenerateMethodHelperBase(t, attr.HashFamily, attr.OutputBit, 2)
+		for i, hash := range hashes {
+			expected := (base[0]%m + uint64(i)*(base[1]%m)) % m
+			if hash != expected {
+				t.Fatalf("%s: g_%d = %d != h1 + i * h2 = %d", attr.HashFamily, i, hash, expected)
+			}
+		}
+	}
+}
+
+func TestGenerateMethodTripleHashing(t *testing.T) {
+	const m = 1 << 20
+	g, _ := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "triple-hashing")
+	hashes, err := g.GenerateHash([]byte("sample"), 0, m, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := testGenerateMethodHelperBase(t, "murmur3Hash128Default", 128, 3)
+	for i, hash := range hashes {
+		n := uint64(i)
+		expected := (base[0] + n*base[1] + n*(n-1)/2*base[2]) % m
+		if hash != expected {
+			t.Fatalf("g_%d = %d != %d", i, hash, expected)
+		}
+	}
+}
+
+func TestGenerateMethodIndependentSeeds(t *testing.T) {
+	g, _ := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "independent-seeds")
+	hf, _ := hasher.NewHashFunction[uint64]("murmur3Hash128Default", 64, 128)
+	hashes, _ := g.GenerateHash([]byte("sample"), 5, 0, 4)
+	for i, hash := range hashes {
+		expected, _ := hf([]byte("sample"), 5+uint64(i))
+		if hash != expected[0] {
+			t.Fatalf("g_%d = %d != first word of seed %d", i, hash, 5+i)
+		}
+	}
+}
+
+func TestGenerateMethodRegister(t *testing.T) {
+	constant := hasher.GenerateMethodFunc[uint64](func(dst []uint64, hashInto hasher.HashIntoFunction[uint64], data []byte, seed uint64, hashCeil uint, times uint) ([]uint64, error) {
+		for i := uint(0); i < times; i++ {
+			dst = append(dst, uint64(i))
+		}
+		return dst, nil
+	})
+	if err := hasher.RegisterGenerateMethod[uint64]("testCounting", constant); err != nil {
+		t.Fatal("can't register:", err)
+	}
+	t.Cleanup(func() { hasher.UnregisterGenerateMethod[uint64]("testCounting") })
+	if err := hasher.RegisterGenerateMethod[uint64]("testCounting", constant); err == nil {
+		t.Fatal("duplicate generate method registered")
+	}
+	if err := hasher.RegisterGenerateMethod[uint64]("standard", constant); err == nil {
+		t.Fatal("builtin generate method overridden")
+	}
+	if err := hasher.RegisterGenerateMethod[uint64]("testNil", nil); err == nil {
+		t.Fatal("nil generate method registered")
+	}
+
+	g, err := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "testCounting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes, _ := g.GenerateHash([]byte("sample"), 0, 100, 3); !slices.Equal(hashes, []uint64{0, 1, 2}) {
+		t.Fatal("registered generate method not used:", hashes)
+	}
+	// only registered for uint64
+	if _, err := hasher.NewHashGenerator[uint32]("halfSipHash24", 32, 32, "testCounting", hasher.WithKey([16]byte{})); err == nil {
+		t.Fatal("uint64 generate method used for uint32")
+	}
+	if err := hasher.UnregisterGenerateMethod[uint64]("standard"); err == nil {
+		t.Fatal("builtin generate method unregistered")
+	}
+	if err := hasher.UnregisterGenerateMethod[uint32]("testCounting"); err == nil {
+		t.Fatal("uint64 generate method unregistered for uint32")
+	}
+
+	bf := bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator("xxh3Hash64", 64, 64, "triple-hashing").Build()
+	bf.Add([]byte("item"))
+	if !bf.Contains([]byte("item")) || !strings.Contains(bf.HashAttr(), "triple-hashing") {
+		t.Fatal("triple hashing bloom filter doesn't work")
+	}
+}
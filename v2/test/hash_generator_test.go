@@ -9,10 +9,37 @@ import (
 )
 
 func TestHashGeneratorCreate(t *testing.T) {
-	g, _ := hasher.NewHashGenerator[uint64]("murmur3", 64, 128, "extended-double-hashing")
+	g, _ := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "extended-double-hashing")
 	data, err := g.GenerateHash([]byte("sample"), uint64(13), 17, 4)
 	if err != nil {
 		log.Fatal("damn", err)
 	}
 	fmt.Println("data:", data)
 }
+
+func TestHashGeneratorTimes(t *testing.T) {
+	hashFuncAttrList := []hasher.HashAttribute{
+		{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128},
+		{HashFamily: "murmur3Hash64Spaolacci", PlatformBit: 64, OutputBit: 64},
+		{HashFamily: "murmur3Hash256Bnb", PlatformBit: 64, OutputBit: 256},
+	}
+	methods := hasher.GenerateMethods()
+
+	for _, attr := range hashFuncAttrList {
+		for _, method := range methods {
+			g, err := hasher.NewHashGenerator[uint64](attr.HashFamily, attr.PlatformBit, attr.OutputBit, method)
+			if err != nil {
+				t.Fatal("can't create hash generator:", err)
+			}
+			for _, times := range []uint{1, 2, 3, 5, 7} {
+				hashes, err := g.GenerateHash([]byte("sample"), 0, 1<<20, times)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if uint(len(hashes)) != times {
+					t.Fatalf("%s: expected %d hashes, got %d (%v)", g, times, len(hashes), hashes)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/register"
+)
+
+func TestHashIndexRange(t *testing.T) {
+	for _, mode := range []hasher.IndexMode{hasher.MultiplyShiftIndex, hasher.MaskIndex, hasher.ModuloIndex} {
+		for _, n := range []uint{1, 7, 1000, 1 << 20, 95851} {
+			ix64, err := hasher.NewIndexer[uint64](n, mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ix32, _ := hasher.NewIndexer[uint32](n, mode)
+			if mode == hasher.MaskIndex && ix64.Size() != hasher.NextPowerOfTwo(n) {
+				t.Fatalf("%s: size %d not rounded up from %d", mode, ix64.Size(), n)
+			}
+			for _, h := range []uint64{0, 1, math.MaxUint64, math.MaxUint64 / 3, 0x8000000000000000} {
+				if idx := ix64.Index(h); idx >= ix64.Size() {
+					t.Fatalf("%s: index %d >= %d", mode, idx, ix64.Size())
+				}
+				if idx := ix32.Index(uint32(h)); idx >= ix32.Size() {
+					t.Fatalf("%s: index %d >= %d", mode, idx, ix32.Size())
+				}
+			}
+		}
+	}
+
+	ix, _ := hasher.NewIndexer[uint64](10, hasher.MultiplyShiftIndex)
+	if ix.Index(0) != 0 || ix.Index(math.MaxUint64) != 9 || ix.Index(1<<63) != 5 {
+		t.Fatal("multiply-shift doesn't scale the hash range onto the slots")
+	}
+	if ix.HashCeil() != math.MaxUint {
+		t.Fatal("multiply-shift needs unreduced hashes")
+	}
+
+	if _, err := hasher.NewIndexer[uint64](0, hasher.ModuloIndex); err == nil {
+		t.Fatal("empty index accepted")
+	}
+	if _, err := hasher.NewIndexer[uint32](1<<32+1, hasher.MultiplyShiftIndex); err == nil {
+		t.Fatal("32-bit hashes can't reach every slot")
+	}
+	for n, expected := range map[uint]uint{0: 1, 1: 1, 2: 2, 3: 4, 1000: 1024, 1024: 1024} {
+		if hasher.NextPowerOfTwo(n) != expected {
+			t.Fatalf("NextPowerOfTwo(%d) = %d", n, hasher.NextPowerOfTwo(n))
+		}
+	}
+}
+
+// slots of multiply-shift must be as even as modulo over random hashes
+func TestHashIndexUniformity(t *testing.T) {
+	const n, samples = 1000, 1000000
+	g, _ := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "standard")
+	for _, mode := range []hasher.IndexMode{hasher.MultiplyShiftIndex, hasher.MaskIndex, hasher.ModuloIndex} {
+		ix, _ := hasher.NewIndexer[uint64](n, mode)
+		counts := make([]float64, ix.Size())
+		for i := 0; i < samples; i++ {
+			hashes, _ := g.GenerateHash([]byte(fmt.Sprint(i)), 0, ix.HashCeil(), 1)
+			counts[ix.Index(hashes[0])]++
+		}
+		expected := float64(samples) / float64(ix.Size())
+		chi2 := 0.0
+		for _, c := range counts {
+			chi2 += (c - expected) * (c - expected) / expected
+		}
+		// chi2 of size - 1 degrees of freedom, mean size - 1, sd sqrt(2 * (size - 1))
+		dof := float64(ix.Size() - 1)
+		if chi2 > dof+5*math.Sqrt(2*dof) {
+			t.Fatalf("%s: chi2 = %.1f over %v slots", mode, chi2, ix.Size())
+		}
+	}
+}
+
+func TestHashIndexBloomFilter(t *testing.T) {
+	const n = 10000
+	m, k := bloomfilter.ClassicBFEstimateParams(0.01, n)
+	keys := testBloomAllocHelperKeys(2 * n)
+	for _, mode := range []hasher.IndexMode{hasher.MultiplyShiftIndex, hasher.MaskIndex, hasher.ModuloIndex} {
+		for _, method := range []string{"standard", "kirsch-mitzenmacher", "extended-double-hashing"} {
+			classic := bloomfilter.NewClassicBFBuilder[uint64]().
+				SetCap(m).
+				SetHashNum(k).
+				SetHashGenerator("murmur3Hash128Default", 64, 128, method).
+				SetIndexMode(mode).
+				Build()
+			counting := bloomfilter.NewCountingBFBuilder[uint64]().
+				SetCap(m).
+				SetHashNum(k).
+				SetHashGenerator("murmur3Hash128Default", 64, 128, method).
+				SetIndexMode(mode).
+				Build()
+			if mode == hasher.MaskIndex && (classic.Cap() != hasher.NextPowerOfTwo(m) || counting.Cap() != classic.Cap()) {
+				t.Fatalf("capacity %d not rounded up to a power of 2", classic.Cap())
+			}
+			for _, key := range keys[:n] {
+				classic.Add(key)
+				counting.Add(key)
+			}
+			falsePositives := 0
+			for _, key := range keys[:n] {
+				if !classic.Contains(key) || !counting.Contains(key) {
+					t.Fatalf("%s, %s: false negative", mode, method)
+				}
+			}
+			for _, key := range keys[n:] {
+				if classic.Contains(key) {
+					falsePositives++
+				}
+			}
+			if fpr := float64(falsePositives) / n; fpr > 0.02 {
+				t.Fatalf("%s, %s: fpr = %.4f", mode, method, fpr)
+			}
+		}
+	}
+
+	scalable, err := bloomfilter.NewScalableBFBuilder[uint64]().SetElems(1000).SetIndexMode(hasher.MaskIndex).Build()
+	if err != nil {
+		t.Fatal("can't build:", err)
+	}
+	for _, key := range keys[:n] {
+		scalable.Add(key)
+	}
+	if scalable.Cap()&(scalable.Cap()-1) == 0 || !scalable.Contains(keys[0]) {
+		t.Fatal("stages are not power-of-2 sized or lost keys")
+	}
+}
+
+func TestHashIndexBloomRegisterSize(t *testing.T) {
+	// a register made for m slots can't hold the rounded up capacity
+	r, _ := register.NewRegister(1000, 1)
+	countR, _ := register.NewRegister(1000, 4)
+	if _, err := bloomfilter.NewClassicBFBuilder[uint64]().SetCap(1000).SetRegister(r.(*register.BitRegister)).SetIndexMode(hasher.MaskIndex).TryBuild(); err == nil {
+		t.Fatal("undersized register accepted")
+	}
+	if bloomfilter.NewClassicBFBuilder[uint64]().SetCap(1000).SetRegister(r.(*register.BitRegister)).SetIndexMode(hasher.MaskIndex).Build() != nil {
+		t.Fatal("Build returned a filter with an undersized register")
+	}
+	if _, err := bloomfilter.NewCountingBFBuilder[uint64]().SetCap(1000).SetCountRegister(countR).SetIndexMode(hasher.MaskIndex).TryBuild(); err == nil {
+		t.Fatal("undersized count register accepted")
+	}
+	if _, err := bloomfilter.NewClassicBFBuilder[uint64]().SetCap(1000).SetRegister(r.(*register.BitRegister)).TryBuild(); err != nil {
+		t.Fatal("register of cap slots rejected:", err)
+	}
+
+	if _, err := bloomfilter.NewClassicBFBuilder[uint64]().SetCap(0).TryBuild(); err == nil {
+		t.Fatal("zero capacity accepted")
+	}
+	if _, err := bloomfilter.NewCountingBFBuilder[uint64]().SetCap(0).TryBuild(); err == nil {
+		t.Fatal("zero capacity accepted")
+	}
+
+	// registers are made by Build when unset
+	bf, err := bloomfilter.NewCountingBFBuilder[uint64]().SetCap(1000).SetIndexMode(hasher.MaskIndex).TryBuild()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf.Add([]byte("item"))
+	if bf.Cap() != 1024 || !bf.Contains([]byte("item")) {
+		t.Fatal("registers not sized by Build")
+	}
+}
+
+func BenchmarkHashIndex(b *testing.B) {
+	for _, mode := range []hasher.IndexMode{hasher.MultiplyShiftIndex, hasher.MaskIndex, hasher.ModuloIndex} {
+		ix, _ := hasher.NewIndexer[uint64](95851, mode)
+		b.Run(mode.String(), func(b *testing.B) {
+			sum := uint(0)
+			for i := 0; i < b.N; i++ {
+				sum += ix.Index(uint64(i) * 0x9e3779b97f4a7c15)
+			}
+			if sum == 1 {
+				b.Log(sum)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher/quality"
+)
+
+// families that ignore the seed, so every hash of a key after the native output words repeats the same index
+var testHashQualityHelperSeedless = map[string]bool{
+	"murmur3Hash256Bnb": true, // bits-and-blooms sum256 is unseeded
+}
+
+// builtin families, listed so registrations of other tests don't change what is checked
+var testHashQualityHelperFamilies = []hasher.HashAttribute{
+	{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128},
+	{HashFamily: "murmur3Hash128Spaolacci", PlatformBit: 64, OutputBit: 128},
+	{HashFamily: "murmur3Hash64Spaolacci", PlatformBit: 64, OutputBit: 64},
+	{HashFamily: "murmur3Hash256Bnb", PlatformBit: 64, OutputBit: 256},
+	{HashFamily: "xxHashCespare", PlatformBit: 64, OutputBit: 64},
+	{HashFamily: "xxHashOneOfOne", PlatformBit: 64, OutputBit: 64},
+	{HashFamily: "xxh3Hash64", PlatformBit: 64, OutputBit: 64},
+	{HashFamily: "xxh3Hash128", PlatformBit: 64, OutputBit: 128},
+	{HashFamily: "sipHash24", PlatformBit: 64, OutputBit: 64},
+	{HashFamily: "sipHash24", PlatformBit: 64, OutputBit: 128},
+	{HashFamily: "halfSipHash24", PlatformBit: 32, OutputBit: 32},
+}
+
+var testHashQualityHelperMethods = []string{
+	"standard", "extended-double-hashing", "kirsch-mitzenmacher-legacy",
+	"kirsch-mitzenmacher", "triple-hashing", "independent-seeds",
+}
+
+func testHashQualityHelperAnalyze(t *testing.T, attr hasher.HashAttribute, method string, cfg quality.Config) *quality.Report {
+	key := hasher.WithKey([16]byte{0x5a, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+	var report *quality.Report
+	var err error
+	if attr.PlatformBit == 32 {
+		report, err = quality.AnalyzeFamily[uint32](attr, method, cfg)
+		if err != nil {
+			report, err = quality.AnalyzeFamily[uint32](attr, method, cfg, key)
+		}
+	} else {
+		report, err = quality.AnalyzeFamily[uint64](attr, method, cfg)
+		if err != nil {
+			report, err = quality.AnalyzeFamily[uint64](attr, method, cfg, key)
+		}
+	}
+	if err != nil {
+		t.Fatal("can't analyze", attr, method, err)
+	}
+	return report
+}
+
+// every builtin family on its own, hashes of 1 key come from successive seeds
+func TestHashQualityFamilies(t *testing.T) {
+	cfg := quality.DefaultConfig()
+	thresholds := quality.DefaultThresholds(cfg)
+	for _, attr := range testHashQualityHelperFamilies {
+		report := testHashQualityHelperAnalyze(t, attr, "standard", cfg)
+		err := report.Check(thresholds)
+		if testHashQualityHelperSeedless[attr.HashFamily] && uint(report.OutputBits) > attr.OutputBit {
+			if err == nil {
+				t.Fatal("repeated indices of a seedless family not detected:", report)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// every builtin generate method on a seeded 128-bit and 64-bit family
+// on a power-of-2 table i * h2 wraps to 0 for every even h2 at i = m / 2, so g_a = g_b whenever (b - a) * h2 = 0 mod m,
+// h1 + i * h2 collides ~1.7x as often as independent indices for k = 7, kirsch-mitzenmacher-legacy ~2x on wide families
+// (enhanced double hashing and triple hashing don't, kirsch-mitzenmacher-legacy on 64-bit families is standard)
+func TestHashQualityGenerateMethods(t *testing.T) {
+	cfg := quality.DefaultConfig()
+	cfg.HashNum = 7
+	thresholds := quality.DefaultThresholds(cfg)
+	for _, attr := range []hasher.HashAttribute{
+		{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128},
+		{HashFamily: "xxh3Hash64", PlatformBit: 64, OutputBit: 64},
+	} {
+		for _, method := range testHashQualityHelperMethods {
+			for _, mode := range []hasher.IndexMode{hasher.MultiplyShiftIndex, hasher.MaskIndex} {
+				cfg.IndexMode = mode
+				report := testHashQualityHelperAnalyze(t, attr, method, cfg)
+				err := report.Check(thresholds)
+				doubleHashing := method == "kirsch-mitzenmacher" || (method == "kirsch-mitzenmacher-legacy" && attr.OutputBit > attr.PlatformBit)
+				if doubleHashing && mode == hasher.MaskIndex {
+					if err == nil || report.CollisionRatio() < 1.3 {
+						t.Fatal("power-of-2 collisions of double hashing not detected:", report)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(mode, err)
+				}
+			}
+		}
+	}
+}
+
+// seed-ignoring families give k copies of 1 hash to double hashing, h1 + i * h2 = (i + 1) * h1
+func TestHashQualitySeedlessDoubleHashing(t *testing.T) {
+	xxh3, _ := hasher.NewHashFunction[uint64]("xxh3Hash64", 64, 64)
+	attr := hasher.HashAttribute{HashFamily: "testQualitySeedless", PlatformBit: 64, OutputBit: 64}
+	seedless := func(data []byte, seed uint64) ([]uint64, error) {
+		return xxh3(data, 0)
+	}
+	if err := testHashRegistryHelperRegister(t, attr, seedless); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := quality.DefaultConfig()
+	report := testHashQualityHelperAnalyze(t, attr, "kirsch-mitzenmacher", cfg)
+	if report.MaxAvalancheBias < 0.99 {
+		t.Fatal("even multiples of 1 hash not detected:", report)
+	}
+
+	// xxHashCespare hashes nonzero seeds with the data, seed 0 stays plain XXH64
+	xxh64, _ := hasher.NewHashFunction[uint64]("xxHashCespare", 64, 64)
+	if h, _ := xxh64([]byte("abc"), 0); h[0] != 0x44bc2cf5ad770999 {
+		t.Fatalf("XXH64(abc) = %#x", h[0])
+	}
+	report = testHashQualityHelperAnalyze(t, hasher.HashAttribute{HashFamily: "xxHashCespare", PlatformBit: 64, OutputBit: 64}, "kirsch-mitzenmacher", cfg)
+	if err := report.Check(quality.DefaultThresholds(cfg)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// a registered family over the thresholds fails its check
+func TestHashQualityWeakFamily(t *testing.T) {
+	// sum of 8-byte words, seed added: linear, no avalanche at all
+	weak := func(data []byte, seed uint64) ([]uint64, error) {
+		padded := make([]byte, (len(data)+7)/8*8)
+		copy(padded, data)
+		sum := seed
+		for i := 0; i < len(padded); i += 8 {
+			sum += binary.LittleEndian.Uint64(padded[i:])
+		}
+		return []uint64{sum}, nil
+	}
+	attr := hasher.HashAttribute{HashFamily: "testQualityWeakSum", PlatformBit: 64, OutputBit: 64}
+	if err := hasher.Register(attr, weak); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { hasher.Unregister(attr) })
+
+	cfg := quality.DefaultConfig()
+	cfg.Keys = 20000
+	report := testHashQualityHelperAnalyze(t, attr, "standard", cfg)
+	err := report.Check(quality.DefaultThresholds(cfg))
+	if err == nil {
+		t.Fatal("weak family passed:", report)
+	}
+	for _, check := range []string{"mean avalanche bias", "bit independence bias", "uniformity z"} {
+		if !strings.Contains(err.Error(), check) {
+			t.Fatalf("weak family passed the %s check: %v", check, report)
+		}
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) < 3 {
+		t.Fatal("every exceeded threshold should be reported:", err)
+	}
+
+	if _, err := quality.AnalyzeFamily[uint64](attr, "standard", quality.Config{}); err == nil {
+		t.Fatal("empty config accepted")
+	}
+}
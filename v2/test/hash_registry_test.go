@@ -0,0 +1,178 @@
+package test
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// seeded FNV-1a, returns words 64-bit halves of repeated hashing
+func testHashRegistryHelperFnv(words int) hasher.HashFunction[uint64] {
+	return func(data []byte, seed uint64) ([]uint64, error) {
+		out := make([]uint64, words)
+		for i := range out {
+			h := fnv.New64a()
+			fmt.Fprintf(h, "%d:%d:", seed, i)
+			h.Write(data)
+			out[i] = h.Sum64()
+		}
+		return out, nil
+	}
+}
+
+// registered for the duration of the test, so tests can run repeatedly and in any order
+func testHashRegistryHelperRegister(t *testing.T, attr hasher.HashAttribute, fn hasher.HashFunction[uint64]) error {
+	err := hasher.Register(attr, fn)
+	if err == nil {
+		t.Cleanup(func() { hasher.Unregister(attr) })
+	}
+	return err
+}
+
+func TestHashRegistryRegister(t *testing.T) {
+	attr := hasher.HashAttribute{HashFamily: "testFnv1a", PlatformBit: 64, OutputBit: 128}
+	if err := testHashRegistryHelperRegister(t, attr, testHashRegistryHelperFnv(2)); err != nil {
+		t.Fatal("can't register:", err)
+	}
+	if err := hasher.Register(attr, testHashRegistryHelperFnv(2)); err == nil {
+		t.Fatal("duplicate family registered")
+	}
+	builtin := hasher.HashAttribute{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128}
+	if err := hasher.Register(builtin, testHashRegistryHelperFnv(2)); err == nil {
+		t.Fatal("builtin family overridden")
+	}
+	keyed := hasher.HashAttribute{HashFamily: "sipHash24", PlatformBit: 64, OutputBit: 64}
+	if err := hasher.Register(keyed, testHashRegistryHelperFnv(1)); err == nil {
+		t.Fatal("keyed family overridden")
+	}
+
+	hf, err := hasher.NewHashFunction[uint64]("testFnv1a", 64, 128)
+	if err != nil {
+		t.Fatal("registered family not found:", err)
+	}
+	if out, _ := hf([]byte("item"), 7); len(out) != 2 {
+		t.Fatal("wrong output length", len(out))
+	}
+
+	if !slices.Contains(hasher.Families(), attr) {
+		t.Fatal("registered family not listed")
+	}
+
+	// usable by every builder
+	bf := bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator("testFnv1a", 64, 128, "standard").Build()
+	bf.Add([]byte("item"))
+	if !bf.Contains([]byte("item")) || !strings.Contains(bf.HashAttr(), "testFnv1a") {
+		t.Fatal("registered family isn't used by the builder")
+	}
+
+	if err := hasher.Unregister(builtin); err == nil {
+		t.Fatal("builtin family unregistered")
+	}
+	if err := hasher.Unregister(attr); err != nil {
+		t.Fatal("can't unregister:", err)
+	}
+	if _, err := hasher.NewHashFunction[uint64]("testFnv1a", 64, 128); err == nil || slices.Contains(hasher.Families(), attr) {
+		t.Fatal("unregistered family is still available")
+	}
+	if !bf.Contains([]byte("item")) {
+		t.Fatal("filter lost its hash function")
+	}
+}
+
+func TestHashRegistryValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		attr hasher.HashAttribute
+		fn   hasher.HashFunction[uint64]
+	}{
+		{"nil function", hasher.HashAttribute{HashFamily: "testNil", PlatformBit: 64, OutputBit: 64}, nil},
+		{"platform bit", hasher.HashAttribute{HashFamily: "testPlatform", PlatformBit: 32, OutputBit: 64}, testHashRegistryHelperFnv(1)},
+		{"output bit", hasher.HashAttribute{HashFamily: "testOutput", PlatformBit: 64, OutputBit: 96}, testHashRegistryHelperFnv(1)},
+		{"output length", hasher.HashAttribute{HashFamily: "testLength", PlatformBit: 64, OutputBit: 256}, testHashRegistryHelperFnv(2)},
+	} {
+		if err := testHashRegistryHelperRegister(t, tc.attr, tc.fn); err == nil {
+			t.Fatal("registered with invalid", tc.name)
+		}
+		if _, err := hasher.NewHashFunction[uint64](tc.attr.HashFamily, tc.attr.PlatformBit, tc.attr.OutputBit); err == nil {
+			t.Fatal("rejected family is available:", tc.name)
+		}
+	}
+
+	if err := hasher.Register(hasher.HashAttribute{HashFamily: "testUint", PlatformBit: 64, OutputBit: 64}, func(data []byte, seed uint) ([]uint, error) {
+		return []uint{0}, nil
+	}); err == nil {
+		t.Fatal("registered platform-dependent uint family")
+	}
+
+	// a function whose output length varies is caught per call
+	calls := 0
+	attr := hasher.HashAttribute{HashFamily: "testFlaky", PlatformBit: 64, OutputBit: 64}
+	if err := testHashRegistryHelperRegister(t, attr, func(data []byte, seed uint64) ([]uint64, error) {
+		calls++
+		if calls > 1 {
+			return []uint64{1, 2}, nil
+		}
+		return []uint64{1}, nil
+	}); err != nil {
+		t.Fatal("can't register:", err)
+	}
+	hf, _ := hasher.NewHashFunction[uint64]("testFlaky", 64, 64)
+	if _, err := hf([]byte("item"), 0); err == nil {
+		t.Fatal("output length mismatch not reported")
+	}
+}
+
+func TestHashRegistryFamilies(t *testing.T) {
+	families := hasher.Families()
+	for _, expected := range []hasher.HashAttribute{
+		{HashFamily: "murmur3Hash128Default", PlatformBit: 64, OutputBit: 128},
+		{HashFamily: "xxh3Hash64", PlatformBit: 64, OutputBit: 64},
+		{HashFamily: "sipHash24", PlatformBit: 64, OutputBit: 128},
+		{HashFamily: "halfSipHash24", PlatformBit: 32, OutputBit: 32},
+	} {
+		if !slices.Contains(families, expected) {
+			t.Fatal("missing family", expected)
+		}
+	}
+	if !slices.IsSortedFunc(families, func(a, b hasher.HashAttribute) int {
+		return strings.Compare(a.HashFamily, b.HashFamily)
+	}) {
+		t.Fatal("families are not sorted")
+	}
+}
+
+func TestHashRegistryConcurrency(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			attr := hasher.HashAttribute{HashFamily: fmt.Sprintf("testConcurrent%d", i), PlatformBit: 64, OutputBit: 64}
+			if err := testHashRegistryHelperRegister(t, attr, testHashRegistryHelperFnv(1)); err != nil {
+				t.Error("can't register:", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := hasher.NewHashFunction[uint64]("xxh3Hash64", 64, 64); err != nil {
+					t.Error("builtin family lost:", err)
+				}
+				hasher.Families()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 16; i++ {
+		if _, err := hasher.NewHashFunction[uint64](fmt.Sprintf("testConcurrent%d", i), 64, 64); err != nil {
+			t.Fatal("concurrently registered family not found:", err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/cardinality/hyperloglog"
+)
+
+func testHyperLogLogHelperFill(plusPlus bool, p uint, from, to int) *hyperloglog.HyperLogLog[uint64] {
+	hll, _ := hyperloglog.NewHyperLogLogBuilder[uint64]().SetPrecision(p).SetPlusPlus(plusPlus).Build()
+	for i := from; i < to; i++ {
+		hll.Add([]byte(fmt.Sprintf("data %b", i)))
+	}
+	return hll
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	for _, plusPlus := range []bool{false, true} {
+		// sparse + sparse, sparse + dense and dense + dense
+		for _, sizes := range [][2]int{{200, 300}, {200, 50000}, {50000, 80000}} {
+			a := testHyperLogLogHelperFill(plusPlus, 14, 0, sizes[0])
+			b := testHyperLogLogHelperFill(plusPlus, 14, sizes[0]/2, sizes[0]/2+sizes[1])
+			whole := testHyperLogLogHelperFill(plusPlus, 14, 0, sizes[0]/2+sizes[1])
+
+			if err := a.Merge(b); err != nil {
+				t.Fatal("can't merge:", err)
+			}
+			fmt.Printf(
+				"plusPlus = %v, sizes = %v, merged = %d, whole = %d\n",
+				plusPlus, sizes, a.Cardinality(), whole.Cardinality(),
+			)
+			// merging is lossless: same registers as a sketch of the whole stream
+			if a.Cardinality() != whole.Cardinality() {
+				t.Fatalf("merged estimate %d != whole estimate %d", a.Cardinality(), whole.Cardinality())
+			}
+		}
+	}
+
+	// merging sparse lists keeps the pending buffer at its size, the result doesn't go dense early
+	sparseA := testHyperLogLogHelperFill(true, 14, 0, 1000)
+	sparseB := testHyperLogLogHelperFill(true, 14, 1000, 2000)
+	sparseWhole := testHyperLogLogHelperFill(true, 14, 0, 2000)
+	if err := sparseA.Merge(sparseB); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+	if !sparseWhole.IsSparse() || !sparseA.IsSparse() {
+		t.Fatalf("merged sparse sketch went dense at %d bytes", sparseA.ByteSize())
+	}
+
+	// the merged-in sketch keeps its pending entries unflushed, in sparse and dense targets alike
+	denseA := testHyperLogLogHelperFill(true, 14, 0, 50000)
+	sparseBytes := sparseB.ByteSize()
+	if err := denseA.Merge(sparseB); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+	if sparseB.ByteSize() != sparseBytes {
+		t.Fatalf("merge modified its argument (%d != %d bytes)", sparseB.ByteSize(), sparseBytes)
+	}
+
+	// merging a sketch into itself changes nothing
+	self := testHyperLogLogHelperFill(true, 14, 0, 1000)
+	selfCardinality := self.Cardinality()
+	if err := self.Merge(self); err != nil || self.Cardinality() != selfCardinality {
+		t.Fatalf("self merge: %v, %d != %d", err, self.Cardinality(), selfCardinality)
+	}
+
+	a := testHyperLogLogHelperFill(false, 14, 0, 10)
+	b := testHyperLogLogHelperFill(false, 12, 0, 10)
+	if err := a.Merge(b); err == nil {
+		t.Fatal("expected mismatched precision error")
+	}
+	c, _ := hyperloglog.NewHyperLogLogBuilder[uint64]().
+		SetHashGenerator("murmur3Hash256Bnb", 64, 256, "standard").
+		Build()
+	if err := a.Merge(c); err == nil {
+		t.Fatal("expected mismatched hash error")
+	}
+	if err := a.Merge(testHyperLogLogHelperFill(true, 14, 0, 10)); err == nil {
+		t.Fatal("expected mismatched plusPlus error")
+	}
+}
+
+func TestHyperLogLogSetEstimate(t *testing.T) {
+	// |A| = 100k, |B| = 60k, |A n B| = 40k, |A u B| = 120k
+	a := testHyperLogLogHelperFill(false, 14, 0, 100000)
+	b := testHyperLogLogHelperFill(false, 14, 60000, 120000)
+
+	union, err := hyperloglog.UnionEstimate(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intersection, err := hyperloglog.IntersectionEstimate(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("union = %.0f +- %.0f, intersection = %.0f +- %.0f\n",
+		union.Value, union.StdError, intersection.Value, intersection.StdError)
+
+	if math.Abs(union.Value-120000) > 4*union.StdError {
+		t.Fatalf("union estimate %.0f too far from 120000", union.Value)
+	}
+	if math.Abs(intersection.Value-40000) > 4*intersection.StdError {
+		t.Fatalf("intersection estimate %.0f too far from 40000", intersection.Value)
+	}
+
+	many := make([]*hyperloglog.HyperLogLog[uint64], hyperloglog.MaxIntersectionSketches)
+	for i := range many {
+		many[i] = a
+	}
+	if _, err := hyperloglog.IntersectionEstimate(a, many...); err == nil {
+		t.Fatal("expected too many sketches error")
+	}
+
+	// inputs are left untouched
+	if a.Cardinality() > 110000 {
+		t.Fatalf("union estimate modified input sketch (%d)", a.Cardinality())
+	}
+}
+
+func TestProbCounterMerge(t *testing.T) {
+	a, err := hyperloglog.NewProbCounter("murmur3Hash128Default", 64, 128, "standard")
+	if err != nil {
+		t.Fatal("can't create counter:", err)
+	}
+	b, _ := hyperloglog.NewProbCounter("murmur3Hash128Default", 64, 128, "standard")
+	for i := 0; i < 1000; i++ {
+		a.Add([]byte(fmt.Sprintf("a %b", i)))
+		b.Add([]byte(fmt.Sprintf("b %b", i)))
+	}
+	expected := max(a.Cardinality(), b.Cardinality())
+	if err := a.Merge(b); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+	if a.Cardinality() != expected {
+		t.Fatalf("merged cardinality %d != %d", a.Cardinality(), expected)
+	}
+
+	c, _ := hyperloglog.NewProbCounter("xxHashOneOfOne", 64, 64, "standard")
+	if err := a.Merge(c); err == nil {
+		t.Fatal("expected mismatched hash error")
+	}
+}
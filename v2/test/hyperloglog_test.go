@@ -0,0 +1,74 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/cardinality/hyperloglog"
+)
+
+func TestHyperLogLogCreate(t *testing.T) {
+	hll, err := hyperloglog.NewHyperLogLogBuilder[uint64]().Build()
+	if err != nil {
+		t.Fatal("can't create hyperloglog:", err)
+	}
+	fmt.Printf(
+		"hyperloglog: p=%d, bytes=%d, std error=%.4f, hash=[%s]\n",
+		hll.Precision(), hll.ByteSize(), hll.StdError(), hll.HashAttr(),
+	)
+	if _, err := hyperloglog.NewHyperLogLogBuilder[uint64]().SetPrecision(2).Build(); err == nil {
+		t.Fatal("expected invalid precision error")
+	}
+}
+
+func TestHyperLogLogBasic(t *testing.T) {
+	for _, p := range []uint{10, 14} {
+		hll, _ := hyperloglog.NewHyperLogLogBuilder[uint64]().SetPrecision(p).Build()
+		checkpoints := map[int]bool{10: true, 100: true, 1000: true, 10000: true, 100000: true, 500000: true}
+		for i := 1; i <= 500000; i++ {
+			hll.Add([]byte(fmt.Sprintf("data %b", i)))
+			if !checkpoints[i] {
+				continue
+			}
+			estimate := hll.Cardinality()
+			relErr := math.Abs(float64(estimate)-float64(i)) / float64(i)
+			fmt.Printf("p = %d, n = %d, estimate = %d, error = %.4f\n", p, i, estimate, relErr)
+			// linear counting is near exact for tiny sets, otherwise allow 4 std errors
+			if relErr > 4*hll.StdError() && math.Abs(float64(estimate)-float64(i)) > 1 {
+				t.Fatalf("p = %d, n = %d: estimate %d off by %.4f", p, i, estimate, relErr)
+			}
+		}
+	}
+}
+
+func TestHyperLogLogPlusPlus(t *testing.T) {
+	p := uint(12)
+	hll, _ := hyperloglog.NewHyperLogLogBuilder[uint64]().SetPrecision(p).SetPlusPlus(true).Build()
+	dense, _ := hyperloglog.NewHyperLogLogBuilder[uint64]().SetPrecision(p).Build()
+
+	checkpoints := map[int]bool{10: true, 300: true, 1000: true, 3000: true, 8000: true, 15000: true, 50000: true, 200000: true}
+	for i := 1; i <= 200000; i++ {
+		item := []byte(fmt.Sprintf("data %b", i))
+		hll.Add(item)
+		dense.Add(item)
+		if !checkpoints[i] {
+			continue
+		}
+		estimate := hll.Cardinality()
+		relErr := math.Abs(float64(estimate)-float64(i)) / float64(i)
+		fmt.Printf(
+			"p = %d, n = %d, sparse = %v, bytes = %d, estimate = %d (classic %d), error = %.4f\n",
+			p, i, hll.IsSparse(), hll.ByteSize(), estimate, dense.Cardinality(), relErr,
+		)
+		if relErr > 4*hll.StdError() && math.Abs(float64(estimate)-float64(i)) > 1 {
+			t.Fatalf("n = %d: estimate %d off by %.4f", i, estimate, relErr)
+		}
+		if i <= 300 && (!hll.IsSparse() || hll.ByteSize() >= dense.ByteSize()) {
+			t.Fatalf("n = %d: expected a sparse sketch smaller than %d bytes", i, dense.ByteSize())
+		}
+		if i >= 15000 && hll.IsSparse() {
+			t.Fatalf("n = %d: expected sketch to be promoted to dense", i)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/quantile/kll"
+)
+
+func TestKLLBasic(t *testing.T) {
+	k := uint(200)
+	sketch, err := kll.NewKLLSketchBuilder[float64]().SetK(k).SetSeed(1).Build()
+	if err != nil {
+		t.Fatal("can't create kll sketch:", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 1000000)
+	for i := range values {
+		values[i] = rng.NormFloat64()
+		sketch.Update(values[i])
+	}
+	sort.Float64s(values)
+
+	eps := sketch.NormalizedRankError(false)
+	fmt.Printf("k = %d, eps = %.5f, retained = %d, levels = %d\n", k, eps, sketch.NumRetained(), sketch.NumLevels())
+	for _, q := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99} {
+		estimate, _ := sketch.Quantile(q)
+		trueRank := float64(sort.SearchFloat64s(values, estimate)+1) / float64(len(values))
+		fmt.Printf("q = %.2f: estimate = %.4f, true rank = %.5f\n", q, estimate, trueRank)
+		if math.Abs(trueRank-q) > eps {
+			t.Fatalf("q = %.2f: rank error %.5f exceeds %.5f", q, math.Abs(trueRank-q), eps)
+		}
+	}
+
+	splits := []float64{-1, 0, 1}
+	pmf, _ := sketch.PMF(splits)
+	cdf, _ := sketch.CDF(splits)
+	fmt.Println("pmf =", pmf, "cdf =", cdf)
+	pmfEps := sketch.NormalizedRankError(true)
+	for i, split := range splits {
+		exact := float64(sort.SearchFloat64s(values, split)) / float64(len(values))
+		if math.Abs(cdf[i]-exact) > pmfEps {
+			t.Fatalf("cdf(%v) = %.5f, exact = %.5f", split, cdf[i], exact)
+		}
+	}
+	if cdf[len(splits)] != 1 {
+		t.Fatal("cdf must end with 1")
+	}
+
+	if min, _ := sketch.Quantile(0); min != values[0] {
+		t.Fatalf("quantile(0) = %v, expected min %v", min, values[0])
+	}
+	if _, err := sketch.Quantile(-0.1); err == nil {
+		t.Fatal("expected invalid quantile error")
+	}
+	if _, err := sketch.CDF([]float64{1, 0}); err == nil {
+		t.Fatal("expected unsorted splits error")
+	}
+	if _, err := kll.NewKLLSketchBuilder[float64]().SetK(4).Build(); err == nil {
+		t.Fatal("expected invalid k error")
+	}
+	if s, err := kll.NewKLLSketchBuilder[float64]().Build(); err != nil || s.K() != kll.DefaultK {
+		t.Fatal("can't build a default kll sketch:", err)
+	}
+}
+
+func TestKLLMergeDeterministic(t *testing.T) {
+	build := func() *kll.KLLSketch[int] {
+		merged, _ := kll.NewKLLSketch[int](kll.DefaultK, 42)
+		for shard := 0; shard < 10; shard++ {
+			s, _ := kll.NewKLLSketch[int](kll.DefaultK, int64(shard))
+			for i := 0; i < 100000; i++ {
+				s.Update(shard*100000 + i)
+			}
+			merged.Merge(s)
+		}
+		return merged
+	}
+
+	a, b := build(), build()
+	if a.Count() != 1000000 {
+		t.Fatalf("merged count %d != 1000000", a.Count())
+	}
+	// capacities sum to < k / (1 - 2/3) plus the minimum width of the lowest levels
+	if bound := 3*kll.DefaultK + 8*a.NumLevels(); a.NumRetained() == 0 || a.NumRetained() > bound {
+		t.Fatalf("merged sketch retains %d items, bound %d", a.NumRetained(), bound)
+	}
+	qs := []float64{0.05, 0.5, 0.95}
+	qa, _ := a.Quantiles(qs)
+	qb, _ := b.Quantiles(qs)
+	if !slices.Equal(qa, qb) {
+		t.Fatalf("same seeds gave different quantiles: %v vs %v", qa, qb)
+	}
+	eps := a.NormalizedRankError(false)
+	for i, q := range qs {
+		trueRank := float64(qa[i]+1) / 1000000
+		fmt.Printf("merged q = %.2f: estimate = %d, true rank = %.5f\n", q, qa[i], trueRank)
+		if math.Abs(trueRank-q) > eps {
+			t.Fatalf("q = %.2f: merged rank error %.5f exceeds %.5f", q, math.Abs(trueRank-q), eps)
+		}
+	}
+
+	// any ordered type works
+	words, _ := kll.NewKLLSketch[string](kll.MinK, 1)
+	for _, w := range []string{"pear", "apple", "fig", "kiwi", "banana"} {
+		words.Update(w)
+	}
+	if median, _ := words.Quantile(0.5); median != "fig" {
+		t.Fatalf("median = %q, expected fig", median)
+	}
+}
+
+func BenchmarkKLLUpdate(b *testing.B) {
+	sketch, _ := kll.NewKLLSketch[float64](kll.DefaultK, 1)
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 1<<16)
+	for i := range values {
+		values[i] = rng.Float64()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sketch.Update(values[i&(len(values)-1)])
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/similarity/lsh"
+	"github.com/nnurry/probabilistics/v2/similarity/minhash"
+)
+
+func TestLSHEstimateParams(t *testing.T) {
+	for _, threshold := range []float64{0.3, 0.5, 0.8} {
+		bands, rows, err := lsh.LSHEstimateParams(threshold, 128, 0.5, 0.5)
+		if err != nil {
+			t.Fatal("can't estimate params:", err)
+		}
+		fmt.Printf("threshold = %.1f: bands = %d, rows = %d, curve threshold = %.3f\n", threshold, bands, rows, lsh.Threshold(bands, rows))
+		if bands*rows > 128 || math.Abs(lsh.Threshold(bands, rows)-threshold) > 0.1 {
+			t.Fatalf("bad banding %d x %d for threshold %.1f", bands, rows, threshold)
+		}
+	}
+	// penalizing false negatives moves the curve left
+	fpBands, fpRows, _ := lsh.LSHEstimateParams(0.5, 128, 0.9, 0.1)
+	fnBands, fnRows, _ := lsh.LSHEstimateParams(0.5, 128, 0.1, 0.9)
+	if lsh.Threshold(fnBands, fnRows) >= lsh.Threshold(fpBands, fpRows) {
+		t.Fatal("false negative weight should lower the curve threshold")
+	}
+	if _, _, err := lsh.LSHEstimateParams(1.5, 128, 0.5, 0.5); err == nil {
+		t.Fatal("expected invalid threshold error")
+	}
+}
+
+func TestLSHIndex(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m, _ := minhash.NewMinHasherBuilder[uint64]().SetNumHashes(128).Build()
+	index, err := lsh.NewLSHIndexBuilder[uint64]().SetThreshold(0.5, 128, 0.5, 0.5).Build()
+	if err != nil {
+		t.Fatal("can't create lsh index:", err)
+	}
+
+	docs := make([][][]byte, 300)
+	for i := range docs {
+		for j := 0; j < 200; j++ {
+			docs[i] = append(docs[i], []byte(fmt.Sprintf("word %d", rng.Intn(1000000))))
+		}
+		sig, _ := m.Signature(docs[i])
+		if err := index.Insert(fmt.Sprintf("doc %d", i), sig); err != nil {
+			t.Fatal("can't insert:", err)
+		}
+	}
+	sig, _ := m.Signature(docs[0])
+	if err := index.Insert("doc 0", sig); err == nil {
+		t.Fatal("expected duplicate id error")
+	}
+
+	missed, spurious := 0, 0
+	for i, doc := range docs {
+		// near duplicate: ~ 10% of shingles replaced, Jaccard ~ 0.8
+		nearDup := slices.Clone(doc)
+		for j := 0; j < len(nearDup)/10; j++ {
+			nearDup[rng.Intn(len(nearDup))] = []byte(fmt.Sprintf("typo %d", rng.Int()))
+		}
+		sig, _ := m.Signature(nearDup)
+		candidates, _ := index.Query(sig)
+		if !slices.Contains(candidates, fmt.Sprintf("doc %d", i)) {
+			missed++
+		}
+		spurious += len(candidates) - 1
+	}
+	fmt.Printf("bands = %d, rows = %d: missed = %d, spurious = %d over %d queries\n", index.Bands(), index.Rows(), missed, spurious, len(docs))
+	if missed > 3 || spurious > 3 {
+		t.Fatalf("too many misses (%d) or spurious candidates (%d)", missed, spurious)
+	}
+
+	if !index.Remove("doc 0") || index.Remove("doc 0") {
+		t.Fatal("remove should succeed exactly once")
+	}
+	if candidates, _ := index.Query(sig); slices.Contains(candidates, "doc 0") {
+		t.Fatal("removed id is still a candidate")
+	}
+	if index.Len() != uint(len(docs)-1) {
+		t.Fatalf("len %d != %d", index.Len(), len(docs)-1)
+	}
+
+	short, _ := minhash.NewMinHasherBuilder[uint64]().SetNumHashes(16).Build()
+	if _, err := index.Query(short.NewSignature()); err == nil {
+		t.Fatal("expected short signature error")
+	}
+
+	// long enough, but bands of other MinHash setups never collide with the indexed ones
+	for name, builder := range map[string]*minhash.MinHasherBuilder[uint64]{
+		"k":    minhash.NewMinHasherBuilder[uint64]().SetNumHashes(256),
+		"mode": minhash.NewMinHasherBuilder[uint64]().SetNumHashes(128).SetMode(minhash.OnePermutation),
+		"seed": minhash.NewMinHasherBuilder[uint64]().SetNumHashes(128).SetSeed(1),
+		"hash": minhash.NewMinHasherBuilder[uint64]().SetNumHashes(128).SetHashGenerator("xxh3Hash64", 64, 64, "standard"),
+	} {
+		other, err := builder.Build()
+		if err != nil {
+			t.Fatal("can't build minhasher:", err)
+		}
+		sig, _ := other.Signature(docs[1])
+		if _, err := index.Query(sig); err == nil {
+			t.Fatalf("expected mismatched %s error on query", name)
+		}
+		if err := index.Insert("other", sig); err == nil || index.Contains("other") {
+			t.Fatalf("expected mismatched %s error on insert", name)
+		}
+	}
+}
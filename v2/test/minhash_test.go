@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/similarity/minhash"
+)
+
+func testMinHashHelperShingles(from, to int) [][]byte {
+	shingles := [][]byte{}
+	for i := from; i < to; i++ {
+		shingles = append(shingles, []byte(fmt.Sprintf("shingle %d", i)))
+	}
+	return shingles
+}
+
+func TestMinHashJaccard(t *testing.T) {
+	for _, mode := range []minhash.Mode{minhash.KPermutation, minhash.OnePermutation} {
+		m, err := minhash.NewMinHasherBuilder[uint64]().SetNumHashes(256).SetMode(mode).SetSeed(7).Build()
+		if err != nil {
+			t.Fatal("can't create minhasher:", err)
+		}
+		// |A n B| / |A u B| = 500 / 1500
+		a, _ := m.Signature(testMinHashHelperShingles(0, 1000))
+		b, _ := m.Signature(testMinHashHelperShingles(500, 1500))
+		expected := 1.0 / 3
+		estimate, err := a.Jaccard(b)
+		if err != nil {
+			t.Fatal("can't estimate jaccard:", err)
+		}
+		fmt.Printf("%v: jaccard = %.4f, expected = %.4f\n", mode, estimate, expected)
+		if math.Abs(estimate-expected) > 4*m.StdError(expected) {
+			t.Fatalf("%v: jaccard %.4f too far from %.4f", mode, estimate, expected)
+		}
+
+		// merged signature is exactly the signature of the union
+		union, _ := m.Signature(testMinHashHelperShingles(0, 1500))
+		if err := a.Merge(b); err != nil {
+			t.Fatal("can't merge:", err)
+		}
+		if !slices.Equal(a.Values(), union.Values()) {
+			t.Fatalf("%v: merged signature != union signature", mode)
+		}
+
+		// fewer shingles than bins leaves one-permutation bins empty
+		small, _ := m.Signature(testMinHashHelperShingles(0, 40))
+		smaller, _ := m.Signature(testMinHashHelperShingles(10, 40))
+		estimate, _ = small.Jaccard(smaller)
+		fmt.Printf("%v: sparse jaccard = %.4f, expected = %.4f\n", mode, estimate, 0.75)
+		if math.Abs(estimate-0.75) > 4*m.StdError(0.75) {
+			t.Fatalf("%v: sparse jaccard %.4f too far from 0.75", mode, estimate)
+		}
+		if same, _ := small.Jaccard(small); same != 1 {
+			t.Fatalf("%v: self jaccard %.4f != 1", mode, same)
+		}
+	}
+
+	k64, _ := minhash.NewMinHasherBuilder[uint64]().SetNumHashes(64).Build()
+	k128, _ := minhash.NewMinHasherBuilder[uint64]().SetNumHashes(128).Build()
+	if _, err := k64.NewSignature().Jaccard(k128.NewSignature()); err == nil {
+		t.Fatal("expected mismatched signature error")
+	}
+	if _, err := minhash.NewMinHasherBuilder[uint64]().SetNumHashes(0).Build(); err == nil {
+		t.Fatal("expected invalid number of hashes error")
+	}
+}
+
+func TestMinHashEncoding(t *testing.T) {
+	m, _ := minhash.NewMinHasherBuilder[uint64]().SetMode(minhash.OnePermutation).Build()
+	a, _ := m.Signature(testMinHashHelperShingles(0, 100))
+	b, _ := m.Signature(testMinHashHelperShingles(20, 120))
+
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal("can't encode:", err)
+	}
+	fmt.Printf("encoded signature: %d bytes\n", len(data))
+	decoded := &minhash.Signature[uint64]{}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal("can't decode:", err)
+	}
+	if !slices.Equal(decoded.Values(), a.Values()) {
+		t.Fatal("decoded values differ")
+	}
+	direct, _ := a.Jaccard(b)
+	fromDecoded, err := decoded.Jaccard(b)
+	if err != nil || direct != fromDecoded {
+		t.Fatalf("decoded jaccard %v != %v (%v)", fromDecoded, direct, err)
+	}
+	if err := decoded.Add([]byte("x")); err == nil {
+		t.Fatal("expected detached signature error")
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Fatal("expected truncated data error")
+	}
+	// k * 8 wraps around to 0 bytes of values
+	hostile := binary.AppendUvarint(slices.Clone(data[:3]), 1<<61)
+	hostile = binary.AppendUvarint(hostile, 0)
+	hostile = binary.AppendUvarint(hostile, 0)
+	if err := decoded.UnmarshalBinary(hostile); err == nil {
+		t.Fatal("expected truncated data error")
+	}
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/quotientfilter"
+)
+
+func TestQuotientFilterCreate(t *testing.T) {
+	qf, err := quotientfilter.NewQuotientFilterBuilder[uint64]().Build()
+	if err != nil {
+		t.Fatal("can't create quotient filter:", err)
+	}
+	fmt.Printf(
+		"quotient filter: q=%d, r=%d, slots=%d, bytes=%d, hash=[%s]\n",
+		qf.QuotientBits(), qf.RemainderBits(), qf.Cap(), qf.ByteSize(), qf.HashAttr(),
+	)
+}
+
+func testQuotientFilterHelperCounts(t *testing.T, qf *quotientfilter.QuotientFilter[uint64], counts map[string]uint) {
+	for key, count := range counts {
+		if got := qf.Count([]byte(key)); got < count {
+			t.Fatalf("count of %q = %d, expected at least %d", key, got, count)
+		}
+	}
+}
+
+func TestQuotientFilterBasic(t *testing.T) {
+	testN := 700
+	qf, err := quotientfilter.NewQuotientFilterBuilder[uint64]().
+		SetQuotientBits(10).
+		SetRemainderBits(7).
+		SetCountBits(4).
+		Build()
+	if err != nil {
+		t.Fatal("can't create quotient filter:", err)
+	}
+
+	counts := map[string]uint{}
+	for i := 0; i < testN; i++ {
+		key := fmt.Sprintf("data %b", i)
+		counts[key] = uint(i%3 + 1)
+		if err := qf.AddCount([]byte(key), counts[key]); err != nil {
+			t.Fatalf("can't add %q: %v", key, err)
+		}
+	}
+	testQuotientFilterHelperCounts(t, qf, counts)
+	fmt.Printf("entries = %d, total count = %d, load factor = %.2f %%\n", qf.Entries(), qf.TotalCount(), qf.LoadFactor()*100)
+
+	fp := 0
+	for i := 0; i < 10000; i++ {
+		if qf.Contains([]byte(fmt.Sprintf("unadded %b", i))) {
+			fp++
+		}
+	}
+	fmt.Printf("fpr = %.4f\n", float64(fp)/10000)
+
+	// remove every element of even index completely
+	for i := 0; i < testN; i += 2 {
+		key := fmt.Sprintf("data %b", i)
+		for c := uint(0); c < counts[key]; c++ {
+			if !qf.Remove([]byte(key)) {
+				t.Fatalf("can't remove %q", key)
+			}
+		}
+		delete(counts, key)
+	}
+	testQuotientFilterHelperCounts(t, qf, counts)
+
+	total := uint(0)
+	for _, count := range counts {
+		total += count
+	}
+	if qf.TotalCount() != total {
+		t.Fatalf("total count = %d, expected %d", qf.TotalCount(), total)
+	}
+
+	if err := qf.Resize(); err != nil {
+		t.Fatal("can't resize:", err)
+	}
+	if qf.QuotientBits() != 11 || qf.RemainderBits() != 6 {
+		t.Fatalf("unexpected resized bits q=%d, r=%d", qf.QuotientBits(), qf.RemainderBits())
+	}
+	testQuotientFilterHelperCounts(t, qf, counts)
+	if qf.TotalCount() != total {
+		t.Fatalf("total count after resize = %d, expected %d", qf.TotalCount(), total)
+	}
+}
+
+func TestQuotientFilterMerge(t *testing.T) {
+	builder := quotientfilter.NewQuotientFilterBuilder[uint64]().
+		SetQuotientBits(12).
+		SetRemainderBits(5)
+	a, _ := builder.Build()
+	b, _ := builder.Build()
+
+	counts := map[string]uint{}
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("data %b", i)
+		a.Add([]byte(key))
+		counts[key]++
+	}
+	for i := 500; i < 1500; i++ {
+		key := fmt.Sprintf("data %b", i)
+		b.Add([]byte(key))
+		counts[key]++
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+	testQuotientFilterHelperCounts(t, a, counts)
+	if a.TotalCount() != 2000 {
+		t.Fatalf("total count after merge = %d, expected 2000", a.TotalCount())
+	}
+
+	c, _ := builder.SetQuotientBits(11).Build()
+	if err := a.Merge(c); err == nil {
+		t.Fatal("expected mismatched filter error")
+	}
+}
+
+func TestQuotientFilterFull(t *testing.T) {
+	qf, _ := quotientfilter.NewQuotientFilterBuilder[uint64]().
+		SetQuotientBits(4).
+		SetRemainderBits(8).
+		Build()
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		err = qf.Add([]byte(fmt.Sprintf("data %b", i)))
+	}
+	if err == nil {
+		t.Fatal("expected filter full error")
+	}
+	fmt.Println("error:", err)
+}
+
+func TestQuotientFilterMinRemainder(t *testing.T) {
+	if _, err := quotientfilter.NewQuotientFilterBuilder[uint64]().SetRemainderBits(1).Build(); err == nil {
+		t.Fatal("1-bit remainders accepted")
+	}
+
+	// resize down to MinRemainderBits keeps every key, one more step is refused
+	qf, err := quotientfilter.NewQuotientFilterBuilder[uint64]().
+		SetQuotientBits(4).
+		SetRemainderBits(3).
+		Build()
+	if err != nil {
+		t.Fatal("can't create quotient filter:", err)
+	}
+	keys := make([][]byte, 10)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("data %b", i))
+		if err := qf.Add(keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := qf.Resize(); err != nil || qf.RemainderBits() != quotientfilter.MinRemainderBits {
+		t.Fatal("can't resize to 2-bit remainders:", err)
+	}
+	if err := qf.Resize(); err == nil {
+		t.Fatal("resized to 1-bit remainders")
+	}
+	for _, key := range keys {
+		if !qf.Contains(key) {
+			t.Fatalf("false negative %q", key)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package test
+
+// sync.Pool drops buffers at random under the race detector
+const testRaceEnabled = true
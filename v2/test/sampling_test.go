@@ -0,0 +1,140 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/sampling"
+)
+
+func TestReservoirUniform(t *testing.T) {
+	src := rand.NewSource(1)
+	n, k, trials := 1000, 10, 20000
+	counts := make([]float64, n)
+	shardCounts := make([]float64, n)
+	for trial := 0; trial < trials; trial++ {
+		r, err := sampling.NewReservoirBuilder[int]().SetCap(uint(k)).SetRandSource(src).Build()
+		if err != nil {
+			t.Fatal("can't create reservoir:", err)
+		}
+		for i := 0; i < n; i++ {
+			r.Add(i)
+		}
+		for _, item := range r.Sample() {
+			counts[item]++
+		}
+
+		// uneven shards merged must still be uniform over the whole stream
+		a, _ := sampling.NewReservoirBuilder[int]().SetCap(uint(k)).SetRandSource(src).Build()
+		b, _ := sampling.NewReservoirBuilder[int]().SetCap(uint(k)).SetRandSource(src).Build()
+		for i := 0; i < n; i++ {
+			if i < 900 {
+				a.Add(i)
+			} else {
+				b.Add(i)
+			}
+		}
+		if err := a.Merge(b); err != nil {
+			t.Fatal("can't merge:", err)
+		}
+		if a.Count() != uint64(n) || len(a.Sample()) != k {
+			t.Fatalf("merged count %d, sample %d", a.Count(), len(a.Sample()))
+		}
+		for _, item := range a.Sample() {
+			shardCounts[item]++
+		}
+	}
+
+	expected := float64(trials*k) / float64(n)
+	for name, c := range map[string][]float64{"single": counts, "merged": shardCounts} {
+		chi2 := 0.0
+		for _, observed := range c {
+			chi2 += (observed - expected) * (observed - expected) / expected
+		}
+		tail := 0.0
+		for _, observed := range c[900:] {
+			tail += observed
+		}
+		fmt.Printf("%s: chi2 = %.1f (df = %d), share of last 10%% = %.4f\n", name, chi2, n-1, tail/float64(trials*k))
+		// df = 999: mean 999, sd ~ 45
+		if chi2 > 999+5*45 || math.Abs(tail/float64(trials*k)-0.1) > 0.01 {
+			t.Fatalf("%s reservoir is not uniform", name)
+		}
+	}
+
+	if _, err := sampling.NewReservoirBuilder[int]().SetCap(0).Build(); err == nil {
+		t.Fatal("expected invalid capacity error")
+	}
+}
+
+func TestReservoirDeterministic(t *testing.T) {
+	sample := func() []int {
+		r, _ := sampling.NewReservoirBuilder[int]().SetCap(5).SetRandSource(rand.NewSource(42)).Build()
+		for i := 0; i < 100000; i++ {
+			r.Add(i)
+		}
+		return r.Sample()
+	}
+	a, b := sample(), sample()
+	if !slices.Equal(a, b) {
+		t.Fatalf("same source gave different samples: %v vs %v", a, b)
+	}
+}
+
+func TestWeightedReservoir(t *testing.T) {
+	src := rand.NewSource(2)
+	weights := []float64{1, 2, 3, 4, 0}
+	trials := 40000
+	counts := make([]float64, len(weights))
+	mergedCounts := make([]float64, len(weights))
+	for trial := 0; trial < trials; trial++ {
+		r, _ := sampling.NewReservoirBuilder[int]().SetCap(1).SetRandSource(src).BuildWeighted()
+		a, _ := sampling.NewReservoirBuilder[int]().SetCap(1).SetRandSource(src).BuildWeighted()
+		b, _ := sampling.NewReservoirBuilder[int]().SetCap(1).SetRandSource(src).BuildWeighted()
+		for i, w := range weights {
+			r.Add(i, w)
+			if i < 3 {
+				a.Add(i, w)
+			} else {
+				b.Add(i, w)
+			}
+		}
+		counts[r.Sample()[0].Item]++
+		a.Merge(b)
+		mergedCounts[a.Sample()[0].Item]++
+	}
+	for i, w := range weights {
+		p := w / 10
+		fmt.Printf("weight %.0f: p = %.3f, single = %.4f, merged = %.4f\n", w, p, counts[i]/float64(trials), mergedCounts[i]/float64(trials))
+		sd := math.Sqrt(p * (1 - p) / float64(trials))
+		if math.Abs(counts[i]/float64(trials)-p) > 5*sd+1e-9 || math.Abs(mergedCounts[i]/float64(trials)-p) > 5*sd+1e-9 {
+			t.Fatalf("weight %.0f sampled with wrong probability", w)
+		}
+	}
+
+	// long stream: heavy items dominate, jumps skip most of the light ones
+	r, _ := sampling.NewReservoirBuilder[int]().SetCap(100).SetRandSource(src).BuildWeighted()
+	for i := 0; i < 100000; i++ {
+		w := 1.0
+		if i%1000 == 0 {
+			w = 10000
+		}
+		r.Add(i, w)
+	}
+	heavy := 0
+	for _, item := range r.Sample() {
+		if item.Weight == 10000 {
+			heavy++
+		}
+	}
+	fmt.Printf("heavy items in sample = %d of 100, total weight = %.0f\n", heavy, r.TotalWeight())
+	if heavy < 80 {
+		t.Fatalf("only %d heavy items sampled", heavy)
+	}
+	if err := r.Add(0, -1); err == nil {
+		t.Fatal("expected invalid weight error")
+	}
+}
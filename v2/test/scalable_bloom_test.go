@@ -0,0 +1,97 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+)
+
+func TestScalableBloomCreate(t *testing.T) {
+	bf, err := bloomfilter.NewScalableBFBuilder[uint64]().Build()
+	if err != nil {
+		t.Fatal("can't build:", err)
+	}
+	typeName := fmt.Sprintf("%T", bf)
+	fmt.Println("type of bloom filter:", typeName)
+	if bf.Stages() != 1 {
+		t.Fatalf("expected 1 stage after build, got %d", bf.Stages())
+	}
+}
+
+func TestScalableBloomBasic(t *testing.T) {
+	testFp := 0.01
+	testN := uint(1000)
+	addedN := 20 * testN
+	queriedN := 100000
+
+	bf, err := bloomfilter.NewScalableBFBuilder[uint64]().
+		SetFpr(testFp).
+		SetElems(testN).
+		SetGrowth(2).
+		SetTightening(0.85).
+		Build()
+	if err != nil {
+		t.Fatal("can't build:", err)
+	}
+
+	for i := uint(0); i < addedN; i++ {
+		bf.Add([]byte(fmt.Sprintf("data %b", i)))
+	}
+
+	for i := uint(0); i < addedN; i++ {
+		if !bf.Contains([]byte(fmt.Sprintf("data %b", i))) {
+			t.Fatalf("false negative for element %d", i)
+		}
+	}
+
+	fp := 0
+	for i := 0; i < queriedN; i++ {
+		if bf.Contains([]byte(fmt.Sprintf("unadded %b", i))) {
+			fp++
+		}
+	}
+	fpr := float64(fp) / float64(queriedN)
+
+	fmt.Printf(
+		"stages = %d, count = %d, cap = %d, bytes = %d, fpr = %.4f (bound %.4f)\n",
+		bf.Stages(), bf.Count(), bf.Cap(), bf.ByteSize(), fpr, bf.EstimateFpr(),
+	)
+
+	if bf.Stages() < 2 {
+		t.Fatalf("expected filter to grow past 1 stage, got %d", bf.Stages())
+	}
+	if bf.EstimateFpr() > testFp {
+		t.Fatalf("compound fpr bound %.4f exceeds target %.4f", bf.EstimateFpr(), testFp)
+	}
+	if fpr > 2*testFp {
+		t.Fatalf("observed fpr %.4f too far above target %.4f", fpr, testFp)
+	}
+}
+
+func TestScalableBloomParams(t *testing.T) {
+	for _, tc := range []struct {
+		fpr, growth, tightening float64
+		elems                   uint
+	}{
+		{0, 2, 0.85, 1000},
+		{1, 2, 0.85, 1000},
+		{math.NaN(), 2, 0.85, 1000},
+		{0.01, 0.5, 0.85, 1000},
+		{0.01, math.Inf(1), 0.85, 1000},
+		{0.01, 2, 0, 1000},
+		{0.01, 2, 1, 1000},
+		{0.01, 2, 0.85, 0},
+	} {
+		_, err := bloomfilter.NewScalableBFBuilder[uint64]().
+			SetFpr(tc.fpr).
+			SetElems(tc.elems).
+			SetGrowth(tc.growth).
+			SetTightening(tc.tightening).
+			Build()
+		if err == nil {
+			t.Fatalf("expected invalid params error for %+v", tc)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/similarity/simhash"
+)
+
+func TestSimHashFingerprint(t *testing.T) {
+	s, err := simhash.NewSimHasherBuilder().SetHashGenerator("xxHashOneOfOne", 64, 64, "standard").Build()
+	if err != nil {
+		t.Fatal("can't create simhasher:", err)
+	}
+	words := []string{}
+	for i := 0; i < 300; i++ {
+		words = append(words, fmt.Sprintf("word %d", i))
+	}
+	base, err := s.Fingerprint(simhash.Tokens(words...))
+	if err != nil {
+		t.Fatal("can't fingerprint:", err)
+	}
+	edited := slices.Clone(words)
+	edited[10], edited[20] = "edit 1", "edit 2"
+	near, _ := s.Fingerprint(simhash.Tokens(edited...))
+	other, _ := s.Fingerprint(simhash.Tokens(words[150:]...))
+	fmt.Printf("near distance = %d, other distance = %d\n", simhash.HammingDistance(base, near), simhash.HammingDistance(base, other))
+	if simhash.HammingDistance(base, near) > 6 || simhash.HammingDistance(base, other) < 10 {
+		t.Fatal("fingerprint distances don't reflect similarity")
+	}
+
+	// a heavy feature dominates the vote
+	heavy, _ := s.Fingerprint([]simhash.Feature{{Token: []byte("title"), Weight: 1000}, {Token: []byte("noise"), Weight: 1}})
+	title, _ := s.Fingerprint(simhash.Tokens("title"))
+	if heavy != title {
+		t.Fatal("weight is not applied")
+	}
+}
+
+func TestSimHashIndex(t *testing.T) {
+	index, err := simhash.NewIndexBuilder().SetDistance(3).Build()
+	if err != nil {
+		t.Fatal("can't create index:", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	fingerprints := make([]uint64, 20000)
+	for i := range fingerprints {
+		fingerprints[i] = rng.Uint64()
+		index.Insert(fmt.Sprintf("doc %d", i), fingerprints[i])
+	}
+	if err := index.Insert("doc 0", 0); err == nil {
+		t.Fatal("expected duplicate id error")
+	}
+
+	for i := 0; i < 200; i++ {
+		query := fingerprints[i]
+		flips := rng.Intn(5)
+		for _, bit := range rng.Perm(64)[:flips] {
+			query ^= 1 << bit
+		}
+		matches := index.Query(query)
+		found := slices.ContainsFunc(matches, func(m simhash.Match) bool { return m.Id == fmt.Sprintf("doc %d", i) })
+		if found != (flips <= 3) {
+			t.Fatalf("doc %d with %d flipped bits: found = %v", i, flips, found)
+		}
+		// brute force agrees
+		expected := 0
+		for _, fp := range fingerprints {
+			if simhash.HammingDistance(fp, query) <= 3 {
+				expected++
+			}
+		}
+		if len(matches) != expected {
+			t.Fatalf("query %d: %d matches, brute force %d", i, len(matches), expected)
+		}
+	}
+	fmt.Printf("tables = %d, indexed = %d\n", index.Tables(), index.Len())
+
+	if !index.Remove("doc 1") || len(index.Query(fingerprints[1])) != 0 {
+		t.Fatal("removed fingerprint still matches")
+	}
+	if _, err := simhash.NewIndexBuilder().SetDistance(4).SetBlocks(4).Build(); err == nil {
+		t.Fatal("expected invalid blocks error")
+	}
+}
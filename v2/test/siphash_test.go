@@ -0,0 +1,170 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/cardinality/hyperloglog"
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+	"github.com/nnurry/probabilistics/v2/similarity/minhash"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// reference key 00 01 .. 0f, message 00 01 .. (n - 1) from the SipHash paper's test vectors
+func testSipHashHelperInput() ([16]byte, []byte) {
+	var key [16]byte
+	for i := range key {
+		key[i] = byte(i)
+	}
+	data := make([]byte, 64)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	return key, data
+}
+
+func TestSipHashVectors(t *testing.T) {
+	key, data := testSipHashHelperInput()
+
+	sip64, err := hasher.NewKeyedHashFunction[uint64]("sipHash24", 64, 64, key)
+	if err != nil {
+		t.Fatal("sipHash24 is not registered:", err)
+	}
+	for n, expected := range map[int]uint64{
+		0:  0x726fdb47dd0e0e31,
+		1:  0x74f839c593dc67fd,
+		7:  0xab0200f58b01d137,
+		8:  0x93f5f5799a932462,
+		15: 0xa129ca6149be45e5,
+		63: 0x958a324ceb064572,
+	} {
+		if out, _ := sip64(data[:n], 0); out[0] != expected {
+			t.Fatalf("sipHash24 64, len = %d: %#x != %#x", n, out[0], expected)
+		}
+	}
+
+	sip128, _ := hasher.NewKeyedHashFunction[uint64]("sipHash24", 64, 128, key)
+	if out, _ := sip128(data[:0], 0); out[0] != 0xe6a825ba047f81a3 || out[1] != 0x930255c71472f66d {
+		t.Fatalf("sipHash24 128, len = 0: %#x %#x", out[0], out[1])
+	}
+
+	halfSip32, err := hasher.NewKeyedHashFunction[uint32]("halfSipHash24", 32, 32, key)
+	if err != nil {
+		t.Fatal("halfSipHash24 is not registered:", err)
+	}
+	if out, _ := halfSip32(data[:0], 0); out[0] != 0x5b9f35a9 {
+		t.Fatalf("halfSipHash24 32, len = 0: %#x", out[0])
+	}
+}
+
+func testSipHashHelperHyperLogLog(method string, key [16]byte) *hyperloglog.HyperLogLog[uint64] {
+	hll, _ := hyperloglog.NewHyperLogLogBuilder[uint64]().SetHashGenerator("sipHash24", 64, 128, method, hasher.WithKey(key)).Build()
+	return hll
+}
+
+func TestSipHashKeyHandling(t *testing.T) {
+	key, _ := testSipHashHelperInput()
+	otherKey := key
+	otherKey[0] ^= 1
+
+	if _, err := hasher.NewHashGenerator[uint64]("sipHash24", 64, 64, "standard"); err == nil {
+		t.Fatal("expected key required error")
+	}
+	if _, err := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "standard", hasher.WithKey(key)); err == nil {
+		t.Fatal("expected key not supported error")
+	}
+
+	g, err := hasher.NewHashGenerator[uint64]("sipHash24", 64, 128, "standard", hasher.WithKey(key))
+	if err != nil {
+		t.Fatal("can't create keyed generator:", err)
+	}
+	other, _ := hasher.NewHashGenerator[uint64]("sipHash24", 64, 128, "standard", hasher.WithKey(otherKey))
+	a, _ := g.GenerateHash([]byte("item"), 0, 1<<20, 4)
+	b, _ := other.GenerateHash([]byte("item"), 0, 1<<20, 4)
+	if a[0] == b[0] {
+		t.Fatal("different keys gave the same hash")
+	}
+	if g.String() == other.String() {
+		t.Fatal("generators with different keys must not look alike")
+	}
+	same, _ := hasher.NewHashGenerator[uint64]("sipHash24", 64, 128, "standard", hasher.WithKey(key))
+	otherMethod, _ := hasher.NewHashGenerator[uint64]("sipHash24", 64, 128, "triple-hashing", hasher.WithKey(key))
+	if !g.Compatible(*same) || g.Compatible(*other) || g.Compatible(*otherMethod) {
+		t.Fatal("generators are compatible iff family, method and key match")
+	}
+	// merges compare the whole generator, not only the family
+	hll := testSipHashHelperHyperLogLog("standard", key)
+	for _, b := range []*hyperloglog.HyperLogLog[uint64]{
+		testSipHashHelperHyperLogLog("standard", otherKey),
+		testSipHashHelperHyperLogLog("triple-hashing", key),
+	} {
+		if err := hll.Merge(b); err == nil {
+			t.Fatal("expected mismatched hash error")
+		}
+	}
+	if err := hll.Merge(testSipHashHelperHyperLogLog("standard", key)); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+
+	// halfSipHash24 reads a 64-bit key, the check value ignores the bytes it doesn't read
+	upperKey := key
+	upperKey[15] ^= 1
+	half, _ := hasher.NewHashGenerator[uint32]("halfSipHash24", 32, 32, "standard", hasher.WithKey(key))
+	halfUpper, _ := hasher.NewHashGenerator[uint32]("halfSipHash24", 32, 32, "standard", hasher.WithKey(upperKey))
+	halfOther, _ := hasher.NewHashGenerator[uint32]("halfSipHash24", 32, 32, "standard", hasher.WithKey(otherKey))
+	if !half.Compatible(*halfUpper) || half.String() != halfUpper.String() || half.Compatible(*halfOther) {
+		t.Fatal("halfSipHash24 key check must cover exactly key[:8]")
+	}
+	sipUpper, _ := hasher.NewHashGenerator[uint64]("sipHash24", 64, 128, "standard", hasher.WithKey(upperKey))
+	if g.Compatible(*sipUpper) {
+		t.Fatal("sipHash24 key check must cover the whole key")
+	}
+
+	// the key never shows up in printed or encoded forms
+	keyHex := fmt.Sprintf("%x", key[:])
+	m, _ := minhash.NewMinHasherBuilder[uint64]().SetHashGenerator("sipHash24", 64, 128, "standard", hasher.WithKey(key)).Build()
+	sig, _ := m.Signature([][]byte{[]byte("a"), []byte("b")})
+	encoded, _ := sig.MarshalBinary()
+	for _, s := range []string{g.String(), fmt.Sprintf("%v", g), fmt.Sprintf("%+v", *g), fmt.Sprintf("%#v", *g), m.HashAttr(), string(encoded), fmt.Sprintf("%x", encoded)} {
+		if strings.Contains(s, keyHex) || strings.Contains(s, string(key[:])) {
+			t.Fatalf("key leaked in %q", s)
+		}
+	}
+	fmt.Println(g.String())
+
+	// keyed filter through the builder
+	bf := bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator("sipHash24", 64, 128, "standard", hasher.WithKey(key)).Build()
+	bf.Add([]byte("item"))
+	if !bf.Contains([]byte("item")) || !strings.Contains(bf.HashAttr(), "sipHash24") {
+		t.Fatal("keyed bloom filter doesn't work")
+	}
+}
+
+// a keyed family that can't be made must fail the build, never fall back to the unkeyed default
+func TestSipHashBuilderDowngrade(t *testing.T) {
+	key, _ := testSipHashHelperInput()
+	for name, err := range testGenerateMethodHelperBuilders("sipHash24", 64, 128, "standard") {
+		if err == nil || !strings.Contains(err.Error(), "WithKey") {
+			t.Fatal(name, "builder accepted sipHash24 without a key:", err)
+		}
+	}
+	for name, err := range testGenerateMethodHelperBuilders("halfSipHash24", 32, 32, "standard", hasher.WithKey(key)) {
+		if err == nil {
+			t.Fatal(name, "builder accepted a 32-bit keyed family for 64-bit hashes")
+		}
+	}
+	for name, err := range testGenerateMethodHelperBuilders("murmur3Hash128Default", 64, 128, "standard", hasher.WithKey(key)) {
+		if err == nil {
+			t.Fatal(name, "builder accepted a key for an unkeyed family")
+		}
+	}
+	for name, err := range testGenerateMethodHelperBuilders("sipHash24", 64, 128, "standard", hasher.WithKey(key)) {
+		if err != nil {
+			t.Fatal(name, "builder rejected a keyed sipHash24:", err)
+		}
+	}
+	if bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator("sipHash24", 64, 64, "standard").Build() != nil {
+		t.Fatal("Build returned an unkeyed filter for sipHash24")
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/frequency/spacesaving"
+)
+
+// zipf-distributed stream of n draws over keys "key 0".."key (imax - 1)"
+func testSpaceSavingHelperStream(seed int64, n int, imax uint64) ([]string, map[string]uint) {
+	rng := rand.New(rand.NewSource(seed))
+	zipf := rand.NewZipf(rng, 1.2, 1, imax-1)
+	stream := make([]string, n)
+	counts := map[string]uint{}
+	for i := range stream {
+		stream[i] = fmt.Sprintf("key %d", zipf.Uint64())
+		counts[stream[i]]++
+	}
+	return stream, counts
+}
+
+func TestSpaceSavingBasic(t *testing.T) {
+	s, err := spacesaving.NewSpaceSavingBuilder[uint64]().SetCap(200).Build()
+	if err != nil {
+		t.Fatal("can't create space-saving summary:", err)
+	}
+	stream, counts := testSpaceSavingHelperStream(1, 200000, 100000)
+	for _, key := range stream {
+		s.Add([]byte(key), 1)
+	}
+
+	bound := s.TotalCount() / s.Cap()
+	if s.ErrorBound() > bound {
+		t.Fatalf("error bound %d exceeds N / m = %d", s.ErrorBound(), bound)
+	}
+	for key, count := range counts {
+		estimate, estimateErr := s.Estimate([]byte(key))
+		if count > bound && estimate == 0 {
+			t.Fatalf("heavy hitter %q (%d > %d) not monitored", key, count, bound)
+		}
+		if estimate > 0 && (estimate < count || estimate-estimateErr > count) {
+			t.Fatalf("true count %d of %q not in [%d, %d]", count, key, estimate-estimateErr, estimate)
+		}
+	}
+
+	top := s.TopK(10)
+	for i, item := range top {
+		fmt.Printf("#%d %s: count = %d, error = %d, true = %d, guaranteed = %v\n",
+			i+1, item.Key, item.Count, item.Error, counts[string(item.Key)], item.Guaranteed)
+		if i > 0 && item.Count > top[i-1].Count {
+			t.Fatal("top k is not sorted by count")
+		}
+	}
+	if !top[0].Guaranteed || string(top[0].Key) != "key 0" {
+		t.Fatalf("expected guaranteed top item \"key 0\", got %+v", top[0])
+	}
+}
+
+func TestSpaceSavingMerge(t *testing.T) {
+	builder := spacesaving.NewSpaceSavingBuilder[uint64]().SetCap(200)
+	a, _ := builder.Build()
+	b, _ := builder.Build()
+
+	streamA, countsA := testSpaceSavingHelperStream(2, 100000, 100000)
+	streamB, countsB := testSpaceSavingHelperStream(3, 100000, 100000)
+	for _, key := range streamA {
+		a.Add([]byte(key), 1)
+	}
+	for _, key := range streamB {
+		b.Add([]byte(key), 1)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal("can't merge:", err)
+	}
+	if a.TotalCount() != 200000 || a.Len() > a.Cap() {
+		t.Fatalf("unexpected merged summary: total = %d, len = %d", a.TotalCount(), a.Len())
+	}
+
+	for _, item := range a.TopK(20) {
+		count := countsA[string(item.Key)] + countsB[string(item.Key)]
+		if item.Count < count || item.Count-item.Error > count {
+			t.Fatalf("true count %d of %q not in [%d, %d]", count, item.Key, item.Count-item.Error, item.Count)
+		}
+	}
+
+	c, _ := builder.SetCap(100).Build()
+	if err := a.Merge(c); err == nil {
+		t.Fatal("expected mismatched summary error")
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/sampling"
+)
+
+type testSubsetSumHelperSample interface {
+	Add(key string, value string, weight float64) error
+	SubsetSum(predicate func(sampling.Item[string]) bool) sampling.SubsetSumEstimate
+}
+
+func testSubsetSumHelperOrders() ([]string, []string, []float64) {
+	rng := rand.New(rand.NewSource(1))
+	countries := []string{"vn", "us", "de", "jp", "br"}
+	keys, values, weights := []string{}, []string{}, []float64{}
+	for i := 0; i < 2000; i++ {
+		keys = append(keys, fmt.Sprintf("order %d", i))
+		values = append(values, countries[rng.Intn(len(countries))])
+		// pareto revenue, a few huge orders
+		weights = append(weights, 10/math.Pow(rng.Float64(), 0.8))
+	}
+	return keys, values, weights
+}
+
+func TestSubsetSumUnbiased(t *testing.T) {
+	keys, values, weights := testSubsetSumHelperOrders()
+	isVn := func(item sampling.Item[string]) bool { return item.Value == "vn" }
+	truth := 0.0
+	for i := range keys {
+		if values[i] == "vn" {
+			truth += weights[i]
+		}
+	}
+
+	builders := map[string]func(seed uint64) testSubsetSumHelperSample{
+		"priority": func(seed uint64) testSubsetSumHelperSample {
+			s, _ := sampling.NewSubsetSamplerBuilder[uint64, string]().SetCap(100).SetSeed(seed).BuildPriority()
+			return s
+		},
+		"varopt": func(seed uint64) testSubsetSumHelperSample {
+			s, _ := sampling.NewSubsetSamplerBuilder[uint64, string]().SetCap(100).SetSeed(seed).BuildVarOpt()
+			return s
+		},
+	}
+	for name, build := range builders {
+		runs := 300
+		mean, meanVariance, sq := 0.0, 0.0, 0.0
+		for seed := 0; seed < runs; seed++ {
+			s := build(uint64(seed))
+			for i := range keys {
+				s.Add(keys[i], values[i], weights[i])
+			}
+			estimate := s.SubsetSum(isVn)
+			mean += estimate.Value / float64(runs)
+			meanVariance += estimate.Variance / float64(runs)
+			sq += (estimate.Value - truth) * (estimate.Value - truth) / float64(runs)
+		}
+		fmt.Printf("%s: truth = %.0f, mean estimate = %.0f, empirical sd = %.0f, estimated sd = %.0f\n", name, truth, mean, math.Sqrt(sq), math.Sqrt(meanVariance))
+		// mean of 300 runs is within 4 standard errors
+		if math.Abs(mean-truth) > 4*math.Sqrt(sq/float64(runs)) {
+			t.Fatalf("%s: estimate is biased", name)
+		}
+		if ratio := meanVariance / sq; ratio < 0.7 || ratio > 1.5 {
+			t.Fatalf("%s: variance estimate off by %.2fx", name, ratio)
+		}
+	}
+}
+
+func TestSubsetSumMerge(t *testing.T) {
+	keys, values, weights := testSubsetSumHelperOrders()
+	builder := sampling.NewSubsetSamplerBuilder[uint64, string]().SetCap(50).SetSeed(3)
+	whole, _ := builder.BuildPriority()
+	merged, _ := builder.BuildPriority()
+	wholeVarOpt, _ := builder.BuildVarOpt()
+	mergedVarOpt, _ := builder.BuildVarOpt()
+	for p := 0; p < 4; p++ {
+		partition, _ := builder.BuildPriority()
+		partitionVarOpt, _ := builder.BuildVarOpt()
+		for i := p; i < len(keys); i += 4 {
+			partition.Add(keys[i], values[i], weights[i])
+			partitionVarOpt.Add(keys[i], values[i], weights[i])
+			whole.Add(keys[i], values[i], weights[i])
+			wholeVarOpt.Add(keys[i], values[i], weights[i])
+		}
+		if err := merged.Merge(partition); err != nil {
+			t.Fatal("can't merge:", err)
+		}
+		if err := mergedVarOpt.Merge(partitionVarOpt); err != nil {
+			t.Fatal("can't merge:", err)
+		}
+	}
+
+	// hashed priorities: merging partitions gives exactly the single-pass sample
+	all := func(sampling.Item[string]) bool { return true }
+	if merged.Tau() != whole.Tau() || merged.SubsetSum(all) != whole.SubsetSum(all) {
+		t.Fatalf("merged priority sample differs: %v vs %v", merged.SubsetSum(all), whole.SubsetSum(all))
+	}
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	estimate := mergedVarOpt.SubsetSum(all)
+	fmt.Printf("varopt total: truth = %.0f, merged = %.0f, single = %.0f\n", total, estimate.Value, wholeVarOpt.SubsetSum(all).Value)
+	// varopt estimates the total exactly, sum of adjusted weights is preserved
+	if math.Abs(estimate.Value-total) > 1e-6*total || len(mergedVarOpt.Sample()) != 50 {
+		t.Fatalf("merged varopt total %.2f != %.2f", estimate.Value, total)
+	}
+	if mergedVarOpt.Count() != uint64(len(keys)) {
+		t.Fatalf("merged count %d != %d", mergedVarOpt.Count(), len(keys))
+	}
+
+	// survivors of a partition are dropped again with fresh draws, subset sums of merged samples stay unbiased
+	// small orders, the ones a merge drops
+	isSmall := func(item sampling.Item[string]) bool { return item.Weight < 20 }
+	truth := 0.0
+	for i := range keys {
+		if weights[i] < 20 {
+			truth += weights[i]
+		}
+	}
+	runs := 300
+	mean, sq := 0.0, 0.0
+	for seed := 0; seed < runs; seed++ {
+		runBuilder := sampling.NewSubsetSamplerBuilder[uint64, string]().SetCap(50).SetSeed(uint64(seed)).SetRandSource(rand.NewSource(int64(seed)))
+		run, _ := runBuilder.BuildVarOpt()
+		for p := 0; p < 4; p++ {
+			partition, _ := runBuilder.BuildVarOpt()
+			for i := p; i < len(keys); i += 4 {
+				partition.Add(keys[i], values[i], weights[i])
+			}
+			if err := run.Merge(partition); err != nil {
+				t.Fatal("can't merge:", err)
+			}
+		}
+		estimate := run.SubsetSum(isSmall).Value
+		mean += estimate / float64(runs)
+		sq += (estimate - truth) * (estimate - truth) / float64(runs)
+	}
+	fmt.Printf("merged varopt: truth = %.0f, mean estimate = %.0f, empirical sd = %.0f\n", truth, mean, math.Sqrt(sq))
+	if z := (mean - truth) / math.Sqrt(sq/float64(runs)); math.Abs(z) > 4 {
+		t.Fatalf("merged varopt subset sum is biased: z = %.2f", z)
+	}
+
+	other, _ := sampling.NewSubsetSamplerBuilder[uint64, string]().SetCap(50).SetSeed(4).BuildPriority()
+	if err := merged.Merge(other); err == nil {
+		t.Fatal("expected mismatched hash error")
+	}
+	if err := merged.Add("x", "vn", math.NaN()); err == nil {
+		t.Fatal("expected invalid weight error")
+	}
+}
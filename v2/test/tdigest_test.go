@@ -0,0 +1,135 @@
+package test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/quantile/tdigest"
+)
+
+func testQuantileHelperExact(sorted []float64, q float64) float64 {
+	return sorted[int(math.Min(float64(len(sorted)-1), q*float64(len(sorted))))]
+}
+
+func TestTDigestBasic(t *testing.T) {
+	d, err := tdigest.NewTDigestBuilder().Build()
+	if err != nil {
+		t.Fatal("can't create t-digest:", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 200000)
+	for i := range values {
+		// latency-like, log-normal
+		values[i] = math.Exp(rng.NormFloat64())
+		d.Add(values[i], 1)
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+		estimate, _ := d.Quantile(q)
+		exact := testQuantileHelperExact(values, q)
+		rankErr := math.Abs(float64(sort.SearchFloat64s(values, estimate))/float64(len(values)) - q)
+		fmt.Printf("q = %.3f: estimate = %.4f, exact = %.4f, rank error = %.5f\n", q, estimate, exact, rankErr)
+		// tails are more accurate than the median
+		if rankErr > 0.01*math.Sqrt(q*(1-q))*4 {
+			t.Fatalf("q = %.3f: rank error %.5f too large", q, rankErr)
+		}
+		cdf := d.CDF(exact)
+		if math.Abs(cdf-q) > 0.01 {
+			t.Fatalf("cdf(%.4f) = %.4f, expected ~%.3f", exact, cdf, q)
+		}
+	}
+	fmt.Printf("centroids = %d for %.0f values\n", len(d.Centroids()), d.Count())
+
+	if _, err := d.Quantile(1.5); err == nil {
+		t.Fatal("expected invalid quantile error")
+	}
+	if err := d.Add(1, -1); err == nil {
+		t.Fatal("expected invalid weight error")
+	}
+}
+
+func TestTDigestMergeEncoding(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	whole, _ := tdigest.NewTDigest(100)
+	hourly, _ := tdigest.NewTDigest(100)
+	values := []float64{}
+	for minute := 0; minute < 60; minute++ {
+		d, _ := tdigest.NewTDigest(100)
+		for i := 0; i < 1000; i++ {
+			v := rng.ExpFloat64() * float64(minute+1)
+			d.Add(v, 1)
+			whole.Add(v, 1)
+			values = append(values, v)
+		}
+
+		// per-minute digests go through storage before being combined
+		data, err := d.MarshalBinary()
+		if err != nil {
+			t.Fatal("can't encode:", err)
+		}
+		decoded := &tdigest.TDigest{}
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal("can't decode:", err)
+		}
+		if decoded.Count() != d.Count() {
+			t.Fatalf("decoded count %.0f != %.0f", decoded.Count(), d.Count())
+		}
+		hourly.Merge(decoded)
+	}
+	sort.Float64s(values)
+
+	if hourly.Count() != float64(len(values)) {
+		t.Fatalf("merged count %.0f != %d", hourly.Count(), len(values))
+	}
+	for _, q := range []float64{0.01, 0.5, 0.99} {
+		merged, _ := hourly.Quantile(q)
+		direct, _ := whole.Quantile(q)
+		rankErr := math.Abs(float64(sort.SearchFloat64s(values, merged))/float64(len(values)) - q)
+		fmt.Printf("q = %.2f: merged = %.4f, direct = %.4f, exact = %.4f\n", q, merged, direct, testQuantileHelperExact(values, q))
+		if rankErr > 0.01 {
+			t.Fatalf("q = %.2f: merged rank error %.5f too large", q, rankErr)
+		}
+	}
+
+	data, _ := hourly.MarshalBinary()
+	fmt.Printf("encoded hourly digest: %d bytes\n", len(data))
+	if err := (&tdigest.TDigest{}).UnmarshalBinary(data[:len(data)-3]); err == nil {
+		t.Fatal("expected truncated data error")
+	}
+	// header claiming 2^62 centroids
+	hostile := binary.AppendUvarint(slices.Clone(data[:1+3*8]), 1<<62)
+	if err := (&tdigest.TDigest{}).UnmarshalBinary(hostile); err == nil {
+		t.Fatal("expected truncated data error")
+	}
+	// verbose centroids (mean, weight) behind a valid header
+	for _, centroids := range [][]float64{
+		{1, math.NaN()},
+		{1, 0},
+		{1, -1},
+		{1, math.Inf(1)},
+		{math.NaN(), 1},
+		{math.Inf(-1), 1},
+		{2, 1, 1, 1},
+		{1, math.MaxFloat64, 2, math.MaxFloat64},
+	} {
+		forged := append([]byte{1}, data[1:1+3*8]...)
+		forged = binary.AppendUvarint(forged, uint64(len(centroids)/2))
+		for _, v := range centroids {
+			forged = binary.LittleEndian.AppendUint64(forged, math.Float64bits(v))
+		}
+		if err := (&tdigest.TDigest{}).UnmarshalBinary(forged); err == nil {
+			t.Fatalf("expected invalid centroid error for %v", centroids)
+		}
+	}
+	for _, compression := range []float64{math.NaN(), math.Inf(1), 1e18, 5} {
+		if _, err := tdigest.NewTDigestBuilder().SetCompression(compression).Build(); err == nil {
+			t.Fatalf("expected invalid compression error for %v", compression)
+		}
+	}
+}
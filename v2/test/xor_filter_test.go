@@ -0,0 +1,146 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/xorfilter"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+type staticFilter interface {
+	Contains(data []byte) bool
+	Cap() uint
+	ByteSize() uint
+	BitsPerEntry() float64
+}
+
+func testStaticFilterHelperBasic(t *testing.T, name string, f staticFilter, testN int, maxFpr float64) {
+	for i := 0; i < testN; i++ {
+		if !f.Contains([]byte(fmt.Sprintf("data %b", i))) {
+			t.Fatalf("%s: false negative for element %d", name, i)
+		}
+	}
+	fp := 0
+	queriedN := 200000
+	for i := 0; i < queriedN; i++ {
+		if f.Contains([]byte(fmt.Sprintf("unadded %b", i))) {
+			fp++
+		}
+	}
+	fpr := float64(fp) / float64(queriedN)
+	fmt.Printf(
+		"%s: n = %d, cap = %d, bytes = %d, bits per entry = %.2f, fpr = %.5f\n",
+		name, testN, f.Cap(), f.ByteSize(), f.BitsPerEntry(), fpr,
+	)
+	if fpr > maxFpr {
+		t.Fatalf("%s: fpr %.5f exceeds %.5f", name, fpr, maxFpr)
+	}
+}
+
+func TestStaticFilterBasic(t *testing.T) {
+	testN := 50000
+	keys := make([][]byte, testN)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("data %b", i))
+	}
+
+	for _, fpBits := range []uint{8, 16} {
+		builder := xorfilter.NewStaticFilterBuilder[uint64]().SetFingerprintBits(fpBits)
+		// 2^-f expected fpr, allow 2x slack
+		maxFpr := 2.0 / float64(uint(1)<<fpBits)
+
+		xf, err := builder.BuildXor(keys)
+		if err != nil {
+			t.Fatal("can't build xor filter:", err)
+		}
+		testStaticFilterHelperBasic(t, fmt.Sprintf("xor%d", fpBits), xf, testN, maxFpr)
+
+		bff, err := builder.BuildBinaryFuse(keys)
+		if err != nil {
+			t.Fatal("can't build binary fuse filter:", err)
+		}
+		testStaticFilterHelperBasic(t, fmt.Sprintf("binary fuse%d", fpBits), bff, testN, maxFpr)
+	}
+}
+
+func TestStaticFilterSmall(t *testing.T) {
+	for _, testN := range []int{0, 1, 2, 10, 100} {
+		keys := make([][]byte, testN)
+		for i := range keys {
+			keys[i] = []byte(fmt.Sprintf("data %b", i))
+		}
+		builder := xorfilter.NewStaticFilterBuilder[uint64]()
+		if _, err := builder.BuildXor(keys); err != nil {
+			t.Fatalf("can't build xor filter of %d keys: %v", testN, err)
+		}
+		bff, err := builder.BuildBinaryFuse(keys)
+		if err != nil {
+			t.Fatalf("can't build binary fuse filter of %d keys: %v", testN, err)
+		}
+		for _, key := range keys {
+			if !bff.Contains(key) {
+				t.Fatalf("false negative for %q in %d keys", key, testN)
+			}
+		}
+		if bpe := bff.BitsPerEntry(); math.IsNaN(bpe) || math.IsInf(bpe, 0) {
+			t.Fatalf("bits per entry of %d keys is %v", testN, bpe)
+		}
+	}
+
+	empty, _ := xorfilter.NewStaticFilterBuilder[uint64]().BuildXor(nil)
+	if bpe := empty.BitsPerEntry(); bpe != 0 {
+		t.Fatalf("bits per entry of empty xor filter is %v", bpe)
+	}
+}
+
+func TestStaticFilterHashCollision(t *testing.T) {
+	// 12-bit hashes, so 3000 distinct keys share hashes the way 32-bit hashes do at scale
+	attr := hasher.HashAttribute{HashFamily: "testStaticFilterNarrow", PlatformBit: 64, OutputBit: 64}
+	fnv := testHashRegistryHelperFnv(1)
+	if err := testHashRegistryHelperRegister(t, attr, func(data []byte, seed uint64) ([]uint64, error) {
+		hashes, err := fnv(data, seed)
+		hashes[0] &= 1<<12 - 1
+		return hashes, err
+	}); err != nil {
+		t.Fatal("can't register:", err)
+	}
+
+	testN := 3000
+	keys := make([][]byte, testN)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("data %b", i))
+	}
+	builder := xorfilter.NewStaticFilterBuilder[uint64]().SetHashGenerator(attr.HashFamily, 64, 64, "standard")
+	xf, err := builder.BuildXor(keys)
+	if err != nil {
+		t.Fatal("can't build xor filter with colliding hashes:", err)
+	}
+	bff, err := builder.BuildBinaryFuse(keys)
+	if err != nil {
+		t.Fatal("can't build binary fuse filter with colliding hashes:", err)
+	}
+	for _, key := range keys {
+		if !xf.Contains(key) || !bff.Contains(key) {
+			t.Fatalf("false negative for %q", key)
+		}
+	}
+}
+
+func TestStaticFilterDuplicate(t *testing.T) {
+	keys := [][]byte{[]byte("a"), []byte("b"), []byte("a")}
+	builder := xorfilter.NewStaticFilterBuilder[uint64]()
+	if _, err := builder.BuildXor(keys); err == nil {
+		t.Fatal("expected duplicate key error for xor filter")
+	}
+	_, err := builder.BuildBinaryFuse(keys)
+	if err == nil {
+		t.Fatal("expected duplicate key error for binary fuse filter")
+	}
+	fmt.Println("error:", err)
+
+	if _, err := builder.SetFingerprintBits(12).BuildXor(keys[:2]); err == nil {
+		t.Fatal("expected invalid fingerprint bits error")
+	}
+}
@@ -0,0 +1,265 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nnurry/probabilistics/v2/membership/bloomfilter"
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+// reference values from xxhash-rust 0.8.15 (xxh3_64_with_seed, xxh3_128_with_seed, *_with_secret)
+// input byte i = (i * 2654435761) >> 13, custom secret byte i = i * 37 + 11
+var xxh3SeededVectors = []struct {
+	length          int
+	seed            uint64
+	hash64          uint64
+	hash128, low128 uint64
+}{
+	{0, 0x0, 0x2d06800538d394c2, 0x99aa06d3014798d8, 0x6001c324468d497f},
+	{0, 0x1, 0x4dc5b0cc826f6703, 0xd9265cc53bb2b9ae, 0x6131b78f753823cd},
+	{0, 0x9e3779b97f4a7c15, 0x602b0e2cd6662c8b, 0xd142977a2cca554b, 0x4ca5176998171787},
+	{1, 0x0, 0xc44bdff4074eecdb, 0xa6cd5e9392000f6a, 0xc44bdff4074eecdb},
+	{1, 0x1, 0x5eaac1f7b17ef730, 0x3b3052d680068ad3, 0x5eaac1f7b17ef730},
+	{1, 0x9e3779b97f4a7c15, 0x62b185e4e01441a, 0xe366b8c99a31df50, 0x62b185e4e01441a},
+	{2, 0x0, 0x1ca5cfa6a6d57dc2, 0x59264f999f5b7151, 0x1ca5cfa6a6d57dc2},
+	{2, 0x1, 0x1f4b40d3289fd1b6, 0x1288668cf4fd328e, 0x1f4b40d3289fd1b6},
+	{2, 0x9e3779b97f4a7c15, 0xe0bd22ab7317af96, 0xd63543b0064fdff5, 0xe0bd22ab7317af96},
+	{3, 0x0, 0xa1c4a8259b827291, 0x95c705060a313bf8, 0xa1c4a8259b827291},
+	{3, 0x1, 0x5a28f7ea7240f068, 0xac10b5a04484cb34, 0x5a28f7ea7240f068},
+	{3, 0x9e3779b97f4a7c15, 0x2c0f411a2c50b127, 0x3c583043ae3ec80e, 0x2c0f411a2c50b127},
+	{4, 0x0, 0xbb4e3d89ee0b271d, 0xafbf64f9281b8de2, 0xfde8d93ae8794d8e},
+	{4, 0x1, 0x50ff5d89bfbfbe16, 0x8eba2c7baba73ac4, 0xc34176f2cd4f1010},
+	{4, 0x9e3779b97f4a7c15, 0x6feef6740d50b0af, 0x3c53fd28a4bcda94, 0x4a10995d638a994e},
+	{5, 0x0, 0xdafb9806147ded6f, 0xa872e23fe3235763, 0x2be2d19951c07993},
+	{5, 0x1, 0xac9cffcff1daa15f, 0xa302035bd13e8848, 0xab6dc66d5e051d61},
+	{5, 0x9e3779b97f4a7c15, 0x7a8545ef93e356ec, 0xfe702997fbc7f0f5, 0x831b5e0cad180873},
+	{8, 0x0, 0x79d02238b80e37b1, 0x2761698c33953c43, 0x234362aaf47b71a},
+	{8, 0x1, 0x6ccd7b8d3a9816a0, 0x2a7ec9ba4632f72c, 0x23aba615ba2f8f},
+	{8, 0x9e3779b97f4a7c15, 0x9e68dfd280cfe66a, 0x9d0c98607e7f886e, 0xac8ad9e866a0b8c8},
+	{9, 0x0, 0xf64cecc4271ff461, 0xd39db6431d37a74, 0x895c8a562da51412},
+	{9, 0x1, 0x12ca6375970c8ab8, 0xf18a4131de85f87d, 0xa61e7e3f88da4ff},
+	{9, 0x9e3779b97f4a7c15, 0xe11e6253a093b0a0, 0xcc06703115906de9, 0x9ceeec552c902473},
+	{15, 0x0, 0x6f72fd6b5d066049, 0x25bce72548003f03, 0xebab99da883ac189},
+	{15, 0x1, 0x7711c54ad94f55c5, 0x197f7828503ece18, 0x700f097fd1d5c67d},
+	{15, 0x9e3779b97f4a7c15, 0x98a8ca6aa7f9620a, 0x550fcd6de87d3184, 0x4a819cd58153301e},
+	{16, 0x0, 0x222e9aead6bddd51, 0x29be75b0bbbb5284, 0xaafffcec5df2cb27},
+	{16, 0x1, 0xc12c2b61fa652821, 0x6fea86fd33f37708, 0xd5f8e12fffdad4f},
+	{16, 0x9e3779b97f4a7c15, 0x54d48b2367b853f2, 0x58c47d96b734dfa2, 0x4184fcb27ec83364},
+	{17, 0x0, 0x47aad6b375eb4bba, 0xdb7e8f77961e47fd, 0x878751509ecfdb8b},
+	{17, 0x1, 0xa26adb58a3002e42, 0xfe8b4cc98c08f18b, 0xe9eab43764564c51},
+	{17, 0x9e3779b97f4a7c15, 0xd0c99ae6d4d79953, 0x46dc26326b17f23, 0x3c579f4313655434},
+	{32, 0x0, 0x774140158f21ff0a, 0x3e97336e6cbb6ee0, 0xde94574d7a589440},
+	{32, 0x1, 0x5e6109bbdd5b9dd2, 0x5c08e6d39b15c326, 0xddbfb1790fe7ed90},
+	{32, 0x9e3779b97f4a7c15, 0xcaf446cbcfb21649, 0x4f53b32cd772eb7c, 0xc600d9d70a3153df},
+	{33, 0x0, 0x537e7ed26d825e92, 0x3411cfe692c2e51e, 0xe2928442749dbe83},
+	{33, 0x1, 0x7baa01872015c099, 0x4771ba9e69b00cd8, 0x4373e33c2a7fd552},
+	{33, 0x9e3779b97f4a7c15, 0xd2183956181e28b, 0x1d587e2c3cc5d577, 0x9e490b154793257},
+	{64, 0x0, 0x70a70e66815e67e5, 0x4928933168865587, 0x66fa129223be93a5},
+	{64, 0x1, 0xa1d529d8a11754dc, 0xf55010e7d8c15edf, 0xbba996cbdfd3776c},
+	{64, 0x9e3779b97f4a7c15, 0xad3c620216a23b35, 0x86b32032544f10a8, 0xa4245b47ed2a7e35},
+	{65, 0x0, 0x4b4ce7050eeb9559, 0xb6dfa5aac5c63dc2, 0xf5b4ade8de9a76a3},
+	{65, 0x1, 0x314dbb3a6acc67c5, 0x24106f8d4eca7e76, 0x8668c80fa4de7a3c},
+	{65, 0x9e3779b97f4a7c15, 0x3a2d1e2a1cf90aaf, 0x6bf53bc859caa04a, 0x3b8d85a879025e1d},
+	{96, 0x0, 0xa97f9ae93c0ff67a, 0xe83e939b9571947c, 0xb9de9a9696c420fc},
+	{96, 0x1, 0x5bf63e5f819b98f0, 0xbf21f249c2adbb8b, 0xa7e6a696a455c4c6},
+	{96, 0x9e3779b97f4a7c15, 0x4ee40d77fdf1cbd4, 0xfdff14438d52ca20, 0x5ca68551efa57780},
+	{97, 0x0, 0xdd88db1bbaf7326, 0xdd6ad8d4fb1433a9, 0x8ea932450271fde4},
+	{97, 0x1, 0x8a206209e9e77eb1, 0x94b0b9e6efaee893, 0x8f6c743cb79942d7},
+	{97, 0x9e3779b97f4a7c15, 0xecd5cb9efa43c0f7, 0x1c94ac7508069d60, 0x634c5d24ca0f56fa},
+	{128, 0x0, 0x421a9c905c6e66ba, 0xba44fd018231af4c, 0xbbe087d879edcc78},
+	{128, 0x1, 0x3e2b2132b1320a38, 0xfad8917a219383d2, 0x3bcba659dec42d6a},
+	{128, 0x9e3779b97f4a7c15, 0xc63a7eb995d1461e, 0xb3ce7d70100aecb2, 0xe5d58a12ecbbc415},
+	{129, 0x0, 0x9e2414800f83768a, 0x522c922743fd67f1, 0xb8075934107218e5},
+	{129, 0x1, 0x669db1f0c0c00537, 0x30ba64b2555dad4b, 0x34de0b405062472d},
+	{129, 0x9e3779b97f4a7c15, 0xf1e03185164c9a4b, 0xffbad2a7b15c96a4, 0xa161c7ab356d129a},
+	{200, 0x0, 0x20a87db907ce74e4, 0xfc856e6538fc9e49, 0x293b2bb62ee3d385},
+	{200, 0x1, 0x21d572b97c50b08b, 0x79a3a2b51407bf2b, 0xef32010340eae024},
+	{200, 0x9e3779b97f4a7c15, 0xfae81c3db303f62b, 0xaef5fc8ee09d7ff5, 0x5a3edf0619b0bbfa},
+	{240, 0x0, 0xb714c5fd22744964, 0x4f49ccc8526aa7ad, 0x407883ea5ef95b9a},
+	{240, 0x1, 0x4a3463ecf1ce3dff, 0x8e7de74f79323d24, 0x1b0471bffe8e9c93},
+	{240, 0x9e3779b97f4a7c15, 0xda8b158566cf41e0, 0x3eea9e0467dc0187, 0xb97819e2dceb8523},
+	{241, 0x0, 0xbc424a2c480dd281, 0x50b62ee1ee6455a7, 0xbc424a2c480dd281},
+	{241, 0x1, 0xf4014562799325a9, 0x711749eb1bd7c954, 0xf4014562799325a9},
+	{241, 0x9e3779b97f4a7c15, 0xdbcb360abf2ca85d, 0xf899b32ec0df0d9, 0xdbcb360abf2ca85d},
+	{255, 0x0, 0x155baa5891f7606f, 0x13c4fb2eb194824f, 0x155baa5891f7606f},
+	{255, 0x1, 0x9f6ba70b03c69ed5, 0x152b18f43128d397, 0x9f6ba70b03c69ed5},
+	{255, 0x9e3779b97f4a7c15, 0x6b281c5f1253f92b, 0xcc390fa56a92f68d, 0x6b281c5f1253f92b},
+	{256, 0x0, 0x2d040b1ab40f0d78, 0x20d618055259b36c, 0x2d040b1ab40f0d78},
+	{256, 0x1, 0xfffbeda06bdcd79e, 0x98b3164ae0f03ca5, 0xfffbeda06bdcd79e},
+	{256, 0x9e3779b97f4a7c15, 0xc9ff3e475003261f, 0x1e88035cabaa3fb1, 0xc9ff3e475003261f},
+	{1024, 0x0, 0x1fd15e7d36f5e1bc, 0x53bd178b75ab292e, 0x1fd15e7d36f5e1bc},
+	{1024, 0x1, 0x8bd0f37fca810004, 0x34899998f7b7e412, 0x8bd0f37fca810004},
+	{1024, 0x9e3779b97f4a7c15, 0x249cd8f9ad0ea839, 0xcf4085274abbf647, 0x249cd8f9ad0ea839},
+	{1025, 0x0, 0xfe08e5a874d23fd2, 0xd1ad5f4a3cce4374, 0xfe08e5a874d23fd2},
+	{1025, 0x1, 0x99fa39e032f76d65, 0x28d47c86cf86c00d, 0x99fa39e032f76d65},
+	{1025, 0x9e3779b97f4a7c15, 0x455ead76fa138d0a, 0xd70f8dc91c37b48a, 0x455ead76fa138d0a},
+	{2048, 0x0, 0x81ec4a6a9ee23d55, 0x2e141090da5502aa, 0x81ec4a6a9ee23d55},
+	{2048, 0x1, 0xbb3049d6718494cf, 0x30df80e5c2d94280, 0xbb3049d6718494cf},
+	{2048, 0x9e3779b97f4a7c15, 0xdf66bcf56366e0fb, 0xf4649ce72cc55b54, 0xdf66bcf56366e0fb},
+	{2500, 0x0, 0x8d14accb80b1b109, 0xdc829275d2ad9a76, 0x8d14accb80b1b109},
+	{2500, 0x1, 0x3cbefb72518426e5, 0xf4d1091e491b0e7f, 0x3cbefb72518426e5},
+	{2500, 0x9e3779b97f4a7c15, 0xf32e1d620de515e0, 0x97f5b800f9942c95, 0xf32e1d620de515e0},
+}
+
+var xxh3SecretVectors = []struct {
+	length          int
+	hash64          uint64
+	hash128, low128 uint64
+}{
+	{0, 0x8ac65a048c188fe5, 0xf3029ddce5fb903f, 0x4ed27f7fcccfe3},
+	{1, 0xf4ea53e48806cfc3, 0x8d56391a199efffd, 0xf4ea53e48806cfc3},
+	{2, 0xcd90074e6fae9ea3, 0x78920fc6ed17c7c7, 0xcd90074e6fae9ea3},
+	{3, 0x6d7397b82bd6acd6, 0x6967a5e062e738aa, 0x6d7397b82bd6acd6},
+	{4, 0x2d21fc4c4e2aff5e, 0x151799edfb989a5f, 0x649d4c590cabb900},
+	{5, 0xfaeebd46c9d993db, 0x5a19d1e000e6196e, 0x2197e065d377d38b},
+	{8, 0x804ac33db079c50c, 0x9334807f58169020, 0xcef6cf8d8ed58ff3},
+	{9, 0xbe40ec33e666cb70, 0xa994f3ce91be5c0d, 0xb5de3f17cddfec07},
+	{15, 0x8e6838848726d857, 0xf3e2fbbeaa4249b, 0x301e2f8524710e79},
+	{16, 0x9f072012d2edd75f, 0x47fb4e5010821451, 0x6f7c0d15f48ae11d},
+	{17, 0xf4e97a145208dee4, 0xb7e897e73f2194af, 0x2da225c901becf63},
+	{32, 0xfb2ba998af18ef43, 0xf7d2ade661e4a713, 0x49c146ad6bbd9f04},
+	{33, 0xe8467c68b7e9b76, 0xb27f3bfea59bf203, 0x28780088ad98ca8e},
+	{64, 0xcaf1430f65e42bbf, 0x9d2793bcd30186af, 0x490ceb004a3b937e},
+	{65, 0xc280ed80f383c20, 0xef5d4d3a04801532, 0xd0c471eedb286f5},
+	{96, 0x265b23083f0fc45d, 0xf982ff8da3aa360b, 0x1dbabe537f4b3dea},
+	{97, 0x235d1838e9f61606, 0x70f79fe22ce41397, 0xc1903464d5f93c91},
+	{128, 0x311bb6ac8318cdb3, 0xb5454be5f565212b, 0xaccbe0eafe901c2f},
+	{129, 0x92904eb8646af63a, 0xf47c21961bf90fdf, 0xfb88c6e8d4170389},
+	{200, 0xf5676db92bc34819, 0xd3d78d0dad066232, 0x6c0815a58a0be71d},
+	{240, 0x8fd8f770f1da2ea3, 0x38fe21d4de6bb96a, 0xbe16795432dcf6f6},
+	{241, 0x6cb4523ce73b26ee, 0x3a36fa22fd54dd18, 0x6cb4523ce73b26ee},
+	{255, 0x146eb028be559454, 0xef60cc546ef9a166, 0x146eb028be559454},
+	{256, 0x4be4f5964f3f0a7e, 0x811f2afdb209f35e, 0x4be4f5964f3f0a7e},
+	{1024, 0x7deea9983cecbd4e, 0xc604034628ec8980, 0x7deea9983cecbd4e},
+	{1025, 0x3adbe079a3902caf, 0xf67df85142a7cfce, 0x3adbe079a3902caf},
+	{2048, 0xe28e0800345393f5, 0x5a9d52110b033d8b, 0xe28e0800345393f5},
+	{2500, 0xf014c2b4bd4c159, 0x7fb8c283576a1577, 0xf014c2b4bd4c159},
+}
+
+func testXXH3HelperInput() ([]byte, []byte) {
+	data := make([]byte, 2500)
+	for i := range data {
+		data[i] = byte(uint32(i) * 2654435761 >> 13)
+	}
+	secret := make([]byte, 192)
+	for i := range secret {
+		secret[i] = byte(i*37 + 11)
+	}
+	return data, secret
+}
+
+func TestXXH3Vectors(t *testing.T) {
+	data, secret := testXXH3HelperInput()
+	h64, err := hasher.NewHashFunction[uint64]("xxh3Hash64", 64, 64)
+	if err != nil {
+		t.Fatal("xxh3Hash64 is not registered:", err)
+	}
+	h128, err := hasher.NewHashFunction[uint64]("xxh3Hash128", 64, 128)
+	if err != nil {
+		t.Fatal("xxh3Hash128 is not registered:", err)
+	}
+	for _, v := range xxh3SeededVectors {
+		out64, _ := h64(data[:v.length], v.seed)
+		out128, _ := h128(data[:v.length], v.seed)
+		if out64[0] != v.hash64 || out128[0] != v.hash128 || out128[1] != v.low128 {
+			t.Fatalf("len = %d, seed = %#x: got %#x, (%#x, %#x), expected %#x, (%#x, %#x)",
+				v.length, v.seed, out64[0], out128[0], out128[1], v.hash64, v.hash128, v.low128)
+		}
+	}
+
+	s64, err := hasher.NewXXH3SecretHashFunction(secret, 64)
+	if err != nil {
+		t.Fatal("can't create xxh3 with secret:", err)
+	}
+	s128, _ := hasher.NewXXH3SecretHashFunction(secret, 128)
+	for _, v := range xxh3SecretVectors {
+		out64, _ := s64(data[:v.length], 0)
+		out128, _ := s128(data[:v.length], 0)
+		if out64[0] != v.hash64 || out128[0] != v.hash128 || out128[1] != v.low128 {
+			t.Fatalf("secret, len = %d: got %#x, (%#x, %#x)", v.length, out64[0], out128[0], out128[1])
+		}
+	}
+	if _, err := hasher.NewXXH3SecretHashFunction(secret[:100], 64); err == nil {
+		t.Fatal("expected invalid secret size error")
+	}
+}
+
+func TestXXH3Seeded(t *testing.T) {
+	data, secret := testXXH3HelperInput()
+	s64, _ := hasher.NewXXH3SecretHashFunction(secret, 64)
+	// every length class must react to the seed, including custom secrets on long inputs
+	for _, length := range []int{0, 3, 8, 16, 128, 240, 2500} {
+		for name, hf := range map[string]hasher.HashFunction[uint64]{"default": nil, "secret": s64} {
+			if hf == nil {
+				hf, _ = hasher.NewHashFunction[uint64]("xxh3Hash64", 64, 64)
+			}
+			a, _ := hf(data[:length], 1)
+			b, _ := hf(data[:length], 2)
+			if a[0] == b[0] {
+				t.Fatalf("%s, len = %d: seed is ignored", name, length)
+			}
+		}
+	}
+
+	// 2 words per call, double hashing methods get h1 and h2 from one call
+	g, err := hasher.NewHashGenerator[uint64]("xxh3Hash128", 64, 128, "extended-double-hashing")
+	if err != nil {
+		t.Fatal("can't create hash generator:", err)
+	}
+	hashes, _ := g.GenerateHash([]byte("key"), 0, 1<<20, 8)
+	fmt.Println("xxh3Hash128 extended double hashing:", hashes)
+	if len(hashes) != 8 || hashes[1] == hashes[2] {
+		t.Fatalf("unexpected hashes %v", hashes)
+	}
+}
+
+func TestXXH3SecretGenerator(t *testing.T) {
+	data, secret := testXXH3HelperInput()
+	g, err := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "standard", hasher.WithSecret(secret))
+	if err != nil {
+		t.Fatal("can't create hash generator with secret:", err)
+	}
+	s64, _ := hasher.NewXXH3SecretHashFunction(secret, 64)
+	defaultG, _ := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "standard")
+	for _, length := range []int{0, 3, 16, 240, 2500} {
+		hashes, _ := g.GenerateHash(data[:length], 0, 1<<20, 3)
+		defaultHashes, _ := defaultG.GenerateHash(data[:length], 0, 1<<20, 3)
+		for i, hash := range hashes {
+			expected, _ := s64(data[:length], uint64(i))
+			if hash != expected[0] || hash == defaultHashes[i] {
+				t.Fatalf("len = %d: hash %d = %#x doesn't use the secret", length, i, hash)
+			}
+		}
+	}
+
+	// secrets are told apart like keys, and never printed
+	other := append([]byte(nil), secret...)
+	other[0] ^= 1
+	g2, _ := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "standard", hasher.WithSecret(other))
+	g3, _ := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "standard", hasher.WithSecret(secret))
+	if g.Compatible(*g2) || g.Compatible(*defaultG) || !g.Compatible(*g3) {
+		t.Fatal("secrets are not compared")
+	}
+	fmt.Println(g.String())
+
+	// through a builder
+	bf, err := bloomfilter.NewClassicBFBuilder[uint64]().SetHashGenerator("xxh3Hash128", 64, 128, "kirsch-mitzenmacher", hasher.WithSecret(secret)).TryBuild()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf.Add([]byte("item"))
+	if !bf.Contains([]byte("item")) {
+		t.Fatal("bloom filter with secret doesn't work")
+	}
+
+	if _, err := hasher.NewHashGenerator[uint64]("xxh3Hash64", 64, 64, "standard", hasher.WithSecret(secret[:100])); err == nil {
+		t.Fatal("short secret accepted")
+	}
+	if _, err := hasher.NewHashGenerator[uint64]("murmur3Hash128Default", 64, 128, "standard", hasher.WithSecret(secret)); err == nil {
+		t.Fatal("secret accepted by murmur3")
+	}
+	if _, err := hasher.NewHashGenerator[uint32]("xxh3Hash64", 64, 64, "standard", hasher.WithSecret(secret)); err == nil {
+		t.Fatal("secret accepted for uint32 hashes")
+	}
+}
@@ -0,0 +1,338 @@
+package hasher
+
+import (
+	"fmt"
+	"slices"
+)
+
+// errors when resolving generate methods
+const (
+	UnknownGenerateMethodMsg   = "unknown generate method %q"
+	DuplicateGenerateMethodMsg = "generate method already registered: %q"
+	NilGenerateMethodMsg       = "nil generate method %q"
+	NotRegisteredMethodMsg     = "generate method not added by RegisterGenerateMethod: %q"
+)
+
+// derives `times` hashes of data from 1 hash function, appended to dst
+// hashInto appends the hash words of data under a seed, dst is scratch space for them
+// hashCeil is the range the caller reduces hashes into, 0 or math.MaxUint when it uses the whole hash
+type GenerateMethod[T HashOutType] interface {
+	Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error)
+}
+
+// plain function as a GenerateMethod
+type GenerateMethodFunc[T HashOutType] func(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error)
+
+func (f GenerateMethodFunc[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	return f(dst, hashInto, data, seed, hashCeil, times)
+}
+
+// guarded by registryLock
+var unsignedInt32GenerateMethods = map[string]GenerateMethod[uint32]{
+	"standard":                   standardMethod[uint32]{},
+	"extended-double-hashing":    extendedDoubleHashingMethod[uint32]{},
+	"kirsch-mitzenmacher":        kirschMitzenmacherMethod[uint32]{},
+	"kirsch-mitzenmacher-legacy": kirschMitzenmacherLegacyMethod[uint32]{},
+	"triple-hashing":             tripleHashingMethod[uint32]{},
+	"independent-seeds":          independentSeedsMethod[uint32]{},
+}
+var unsignedInt64GenerateMethods = map[string]GenerateMethod[uint64]{
+	"standard":                   standardMethod[uint64]{},
+	"extended-double-hashing":    extendedDoubleHashingMethod[uint64]{},
+	"kirsch-mitzenmacher":        kirschMitzenmacherMethod[uint64]{},
+	"kirsch-mitzenmacher-legacy": kirschMitzenmacherLegacyMethod[uint64]{},
+	"triple-hashing":             tripleHashingMethod[uint64]{},
+	"independent-seeds":          independentSeedsMethod[uint64]{},
+}
+
+// methods added by RegisterGenerateMethod, by type name and name, guarded by registryLock
+var registeredGenerateMethods = map[[2]string]bool{}
+
+// register method under name for hash generators of T
+func RegisterGenerateMethod[T HashOutType](name string, method GenerateMethod[T]) error {
+	var genericRef T
+	typeName := fmt.Sprintf("%T", genericRef)
+	if typeName != "uint64" && typeName != "uint32" {
+		return fmt.Errorf(UnsupportedHashOutMsg, typeName)
+	}
+	if method == nil {
+		return fmt.Errorf(NilGenerateMethodMsg, name)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	switch typeName {
+	case "uint64":
+		if _, ok := unsignedInt64GenerateMethods[name]; ok {
+			return fmt.Errorf(DuplicateGenerateMethodMsg, name)
+		}
+		unsignedInt64GenerateMethods[name] = any(method).(GenerateMethod[uint64])
+	case "uint32":
+		if _, ok := unsignedInt32GenerateMethods[name]; ok {
+			return fmt.Errorf(DuplicateGenerateMethodMsg, name)
+		}
+		unsignedInt32GenerateMethods[name] = any(method).(GenerateMethod[uint32])
+	}
+	registeredGenerateMethods[[2]string{typeName, name}] = true
+	return nil
+}
+
+// remove a method added by RegisterGenerateMethod for T, builtin methods stay
+func UnregisterGenerateMethod[T HashOutType](name string) error {
+	var genericRef T
+	typeName := fmt.Sprintf("%T", genericRef)
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if !registeredGenerateMethods[[2]string{typeName, name}] {
+		return fmt.Errorf(NotRegisteredMethodMsg, name)
+	}
+	switch typeName {
+	case "uint64":
+		delete(unsignedInt64GenerateMethods, name)
+	case "uint32":
+		delete(unsignedInt32GenerateMethods, name)
+	}
+	delete(registeredGenerateMethods, [2]string{typeName, name})
+	return nil
+}
+
+// names of every registered generate method, sorted
+func GenerateMethods() []string {
+	registryLock.RLock()
+	names := []string{}
+	for name := range unsignedInt64GenerateMethods {
+		names = append(names, name)
+	}
+	for name := range unsignedInt32GenerateMethods {
+		if _, ok := unsignedInt64GenerateMethods[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	registryLock.RUnlock()
+
+	slices.Sort(names)
+	return names
+}
+
+func newGenerateMethod[T HashOutType](name string) (GenerateMethod[T], error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var genericRef T
+	typeName := fmt.Sprintf("%T", genericRef)
+
+	switch typeName {
+	case "uint64":
+		if method, ok := unsignedInt64GenerateMethods[name]; ok {
+			return any(method).(GenerateMethod[T]), nil
+		}
+	case "uint32":
+		if method, ok := unsignedInt32GenerateMethods[name]; ok {
+			return any(method).(GenerateMethod[T]), nil
+		}
+	}
+	return nil, fmt.Errorf(UnknownGenerateMethodMsg, name)
+}
+
+// first n hash words of data, narrow hash functions are called again with the next seeds
+// dst is only used as scratch space, it is returned with its length unchanged
+func baseHashes[T HashOutType](dst []T, hashInto HashIntoFunction[T], data []byte, seed T, n int) ([3]T, []T, error) {
+	var base [3]T
+	var err error
+	start := len(dst)
+	for i := T(0); len(dst)-start < n; i++ {
+		if dst, err = hashInto(dst, data, seed+i); err != nil {
+			return base, nil, err
+		}
+	}
+	copy(base[:n], dst[start:])
+	return base, dst[:start], nil
+}
+
+// a + b mod m for a, b < m, without overflow
+func addMod[T HashOutType](a, b, m T) T {
+	if a >= m-b {
+		return a - (m - b)
+	}
+	return a + b
+}
+
+// hashCeil doesn't narrow the range of T
+func fullRange[T HashOutType](hashCeil uint) bool {
+	return hashCeil == 0 || uint64(hashCeil) >= uint64(^T(0))
+}
+
+func reduce[T HashOutType](h T, hashCeil uint) T {
+	if fullRange[T](hashCeil) {
+		return h
+	}
+	return h % T(hashCeil)
+}
+
+// k hash functions -> hash k times with different seeds, every output word is used
+type standardMethod[T HashOutType] struct{}
+
+func (standardMethod[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	var err error
+	start := len(dst)
+	for i := T(0); uint(len(dst)-start) < times; i++ {
+		if dst, err = hashInto(dst, data, seed+i); err != nil {
+			return nil, err
+		}
+	}
+	// wide hash functions can overshoot when times is not a multiple of their output
+	return dst[:start+int(times)], nil
+}
+
+// http://www.peterd.org/pcd-diss.pdf
+// Adaptive Approximate State Storage
+// 6.5.4 Enhanced double hashing
+// narrow hash functions fall back to standard
+type extendedDoubleHashingMethod[T HashOutType] struct{}
+
+func (extendedDoubleHashingMethod[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	start := len(dst)
+	dst, err := hashInto(dst, data, seed)
+	if err != nil {
+		return nil, err
+	}
+	if len(dst)-start < 2 {
+		return standardMethod[T]{}.Generate(dst[:start], hashInto, data, seed, hashCeil, times)
+	}
+	h0, h1 := dst[start], dst[start+1]
+	dst = dst[:start]
+	if times == 0 {
+		return dst, nil
+	}
+	dst = append(dst, h0)
+	if hashCeil == 0 {
+		for i := uint(1); i < times; i++ {
+			h0 += h1
+			h1 += seed + T(i)
+			dst = append(dst, h0)
+		}
+		return dst, nil
+	}
+	hashCeilT := T(hashCeil)
+	for i := uint(1); i < times; i++ {
+		newseed := seed + T(i)
+		h0 = (h0 + h1) % hashCeilT
+		h1 = (h1 + newseed) % hashCeilT
+		dst = append(dst, h0)
+	}
+	return dst, nil
+}
+
+// g_i = h1 + i * h2 (Kirsch & Mitzenmacher, 2006, Less Hashing, Same Performance)
+type kirschMitzenmacherMethod[T HashOutType] struct{}
+
+func (kirschMitzenmacherMethod[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	if times == 0 {
+		return dst, nil
+	}
+	base, dst, err := baseHashes(dst, hashInto, data, seed, 2)
+	if err != nil {
+		return nil, err
+	}
+	if fullRange[T](hashCeil) {
+		for i := uint(0); i < times; i++ {
+			dst = append(dst, base[0]+T(i)*base[1])
+		}
+		return dst, nil
+	}
+	hashCeilT := T(hashCeil)
+	g, step := base[0]%hashCeilT, base[1]%hashCeilT
+	for i := uint(0); i < times; i++ {
+		dst = append(dst, g)
+		g = addMod(g, step, hashCeilT)
+	}
+	return dst, nil
+}
+
+// the scheme once registered as kirsch-mitzenmacher, not the one of the paper
+// g_i = h1 + sum_j (s + i)^j * h_j over every output word, narrow hash functions fall back to standard
+type kirschMitzenmacherLegacyMethod[T HashOutType] struct{}
+
+func (kirschMitzenmacherLegacyMethod[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	start := len(dst)
+	dst, err := hashInto(dst, data, seed)
+	if err != nil {
+		return nil, err
+	}
+	words := len(dst) - start
+	if words < 2 {
+		return standardMethod[T]{}.Generate(dst[:start], hashInto, data, seed, hashCeil, times)
+	}
+	if times == 0 {
+		return dst[:start], nil
+	}
+	// hashes are generated after the words, then moved over them
+	hashCeilT := T(hashCeil)
+	dst = append(dst, dst[start])
+	seed += 3
+	for i := uint(1); i < times; i++ {
+		finalHash := dst[start]
+		newseed := seed + T(i)
+		powerseed := newseed
+		for j := 1; j < words; j++ {
+			term := powerseed * dst[start+j]
+			if hashCeil != 0 {
+				term %= hashCeilT
+			}
+			finalHash += term
+			powerseed *= newseed
+		}
+		dst = append(dst, finalHash)
+	}
+	copy(dst[start:], dst[start+words:])
+	return dst[:start+int(times)], nil
+}
+
+// g_i = h1 + i * h2 + i * (i - 1) / 2 * h3 (Dillinger & Manolios, 2004, Bloom Filters in Probabilistic Verification)
+type tripleHashingMethod[T HashOutType] struct{}
+
+func (tripleHashingMethod[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	if times == 0 {
+		return dst, nil
+	}
+	base, dst, err := baseHashes(dst, hashInto, data, seed, 3)
+	if err != nil {
+		return nil, err
+	}
+	if fullRange[T](hashCeil) {
+		g, step := base[0], base[1]
+		for i := uint(0); i < times; i++ {
+			dst = append(dst, g)
+			g += step
+			step += base[2]
+		}
+		return dst, nil
+	}
+	hashCeilT := T(hashCeil)
+	g, step, stepIncrement := base[0]%hashCeilT, base[1]%hashCeilT, base[2]%hashCeilT
+	for i := uint(0); i < times; i++ {
+		dst = append(dst, g)
+		g = addMod(g, step, hashCeilT)
+		step = addMod(step, stepIncrement, hashCeilT)
+	}
+	return dst, nil
+}
+
+// 1 hash function call per hash with seeds s, s + 1, ..., only the first word of each is used
+type independentSeedsMethod[T HashOutType] struct{}
+
+func (independentSeedsMethod[T]) Generate(dst []T, hashInto HashIntoFunction[T], data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	var err error
+	for i := uint(0); i < times; i++ {
+		next := len(dst)
+		if dst, err = hashInto(dst, data, seed+T(i)); err != nil {
+			return nil, err
+		}
+		dst[next] = reduce(dst[next], hashCeil)
+		dst = dst[:next+1]
+	}
+	return dst, nil
+}
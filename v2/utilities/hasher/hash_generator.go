@@ -4,102 +4,135 @@ import "fmt"
 
 type HashGenerator[T HashOutType] struct {
 	hashFunction   HashFunction[T]
+	hashInto       HashIntoFunction[T]
 	hashFamily     string
 	platformBit    uint
 	outputBit      uint
 	generateMethod string
+	method         GenerateMethod[T]
+	keyed          bool   // keyed family or xxh3 with a secret
+	keyCheck       uint32 // the key itself is only captured by hashFunction
 }
 
-func NewHashGenerator[T HashOutType](hashFamily string, platformBit uint, outputBit uint, generateMethod string) (*HashGenerator[T], error) {
-	hashFunction, err := NewHashFunction[T](hashFamily, platformBit, outputBit)
+type hashOptions struct {
+	key    [16]byte
+	hasKey bool
+	secret []byte
+}
+
+type HashOption func(*hashOptions)
+
+// key of a keyed family, sipHash24 reads all 128 bits, halfSipHash24 a 64-bit key from key[:8]
+func WithKey(key [16]byte) HashOption {
+	return func(o *hashOptions) {
+		o.key = key
+		o.hasKey = true
+	}
+}
+
+// custom secret of xxh3Hash64 or xxh3Hash128 (uint64 only), at least XXH3SecretSizeMin bytes
+// see NewXXH3SecretHashFunction
+func WithSecret(secret []byte) HashOption {
+	return func(o *hashOptions) {
+		o.secret = secret
+	}
+}
+
+func NewHashGenerator[T HashOutType](hashFamily string, platformBit uint, outputBit uint, generateMethod string, opts ...HashOption) (*HashGenerator[T], error) {
+	options := hashOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	hashAttr := HashAttribute{hashFamily, platformBit, outputBit}
+	keyed := isKeyedHashFunction[T](hashFamily, platformBit, outputBit)
+	secret := options.secret != nil
+	var hashFunction HashFunction[T]
+	var err error
+	switch {
+	case secret && (keyed || options.hasKey || !isSecretHashFamily(hashAttr)):
+		return nil, fmt.Errorf(SecretNotSupportedMsg, hashFamily)
+	case secret:
+		hashFunction, err = newSecretHashFunction[T](hashAttr, options.secret)
+	case keyed && !options.hasKey:
+		return nil, fmt.Errorf(KeyRequiredMsg, hashFamily)
+	case !keyed && options.hasKey:
+		return nil, fmt.Errorf(KeyNotSupportedMsg, hashFamily)
+	case keyed:
+		hashFunction, err = NewKeyedHashFunction[T](hashFamily, platformBit, outputBit, options.key)
+	default:
+		hashFunction, err = NewHashFunction[T](hashFamily, platformBit, outputBit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	method, err := newGenerateMethod[T](generateMethod)
 	if err != nil {
 		return nil, err
 	}
 	hashGenerator := &HashGenerator[T]{
 		hashFunction:   hashFunction,
+		hashInto:       hashIntoFunction(hashAttr, hashFunction),
 		hashFamily:     hashFamily,
 		platformBit:    platformBit,
 		outputBit:      outputBit,
 		generateMethod: generateMethod,
+		method:         method,
+		keyed:          keyed || secret,
+	}
+	switch {
+	case secret:
+		// the builtin allocation-free form would use the default secret
+		hashGenerator.hashInto = wrapHashFunction(hashFunction)
+		hashGenerator.keyCheck = secretCheckValue(options.secret)
+	case keyed:
+		hashGenerator.keyCheck = keyCheckValue(hashFamily, options.key)
 	}
 
 	return hashGenerator, nil
 }
 
+// keyed families show a check value of the key, never the key
 func (g HashGenerator[T]) String() string {
+	keyInfo := ""
+	if g.keyed {
+		keyInfo = fmt.Sprintf(", key check = %08x", g.keyCheck)
+	}
 	return fmt.Sprintf(
-		"hash(x) = [%s;%d;%d], method = %s",
+		"hash(x) = [%s;%d;%d], method = %s%s",
 		g.hashFamily,
 		g.platformBit,
 		g.outputBit,
 		g.generateMethod,
+		keyInfo,
 	)
 }
 
-func (g *HashGenerator[T]) GenerateHash(data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
-	output := make([]T, times)
-	hashCeilT := T(hashCeil)
-	hashes, err := g.hashFunction(data, seed)
-	if err != nil {
-		return nil, err
-	}
+// %#v would print fields otherwise
+func (g HashGenerator[T]) GoString() string {
+	return g.String()
+}
 
-	output = append(output, hashes[0])
+func (g HashGenerator[T]) HashAttribute() HashAttribute {
+	return HashAttribute{g.hashFamily, g.platformBit, g.outputBit}
+}
 
-	if len(hashes) < 2 {
-		// only standard
-		for i := uint(1); i < times; i++ {
-			hashes, err = g.hashFunction(data, seed+T(i))
-			if err != nil {
-				return nil, err
-			}
-			output = append(output, hashes[0])
-		}
-		return output, nil
-	}
+// same family, generate method and key (by its check value), so both hash every input the same
+// structures built from compatible generators can be merged
+func (g HashGenerator[T]) Compatible(other HashGenerator[T]) bool {
+	return g.HashAttribute() == other.HashAttribute() &&
+		g.generateMethod == other.generateMethod &&
+		g.keyed == other.keyed &&
+		g.keyCheck == other.keyCheck
+}
 
-	if g.generateMethod == "extended-double-hashing" {
-		// http://www.peterd.org/pcd-diss.pdf
-		// Adaptive Approximate State Storage
-		// 6.5.4 Enhanced double hashing
+// `times` hashes of data by the generate method
+func (g *HashGenerator[T]) GenerateHash(data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	return g.GenerateHashInto(make([]T, 0, times), data, seed, hashCeil, times)
+}
 
-		for i := uint(1); i < times; i++ {
-			newseed := seed + T(i)
-			hashes[0] = (hashes[0] + hashes[1]) % hashCeilT
-			hashes[1] = (hashes[1] + newseed) % hashCeilT
-			output = append(output, hashes[0])
-		}
-		return output, nil
-	} else if g.generateMethod == "kirsch-mitzenmacher" {
-		// Kirsch-Mitzenmacher for accomodating variable-sized hash slice (just made it up, don't know if it holds valid)
-		seed += 3
-		for i := uint(0); i < times; i++ {
-			finalHash := hashes[0]
-			newseed := seed + T(i)
-			powerseed := newseed
-			for j := 1; j < len(hashes); j++ {
-				finalHash += (powerseed * hashes[j]) % hashCeilT
-				powerseed *= newseed
-			}
-			output = append(output, finalHash)
-		}
-		return output, nil
-	}
-	// standard: k-hash functions -> hash k-times with different seed
-	output = append(output, hashes[1:]...)
-	i := uint(len(hashes))
-	for i < times-1 {
-		hashes, err = g.hashFunction(data, seed+T(i))
-		if err != nil {
-			return nil, err
-		}
-		for _, hash := range hashes {
-			output = append(output, hash)
-			i++
-			if i == times-1 {
-				return output, nil
-			}
-		}
-	}
-	return output, nil
+// GenerateHash reusing the capacity of dst, the result may share its backing array
+// a buffer kept across calls makes the builtin unkeyed families allocation-free
+func (g *HashGenerator[T]) GenerateHashInto(dst []T, data []byte, seed T, hashCeil uint, times uint) ([]T, error) {
+	return g.method.Generate(dst[:0], g.hashInto, data, seed, hashCeil, times)
 }
@@ -1,11 +1,17 @@
 package hasher
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 // errors when init hash functions
 const (
 	NoMatchingHashFamilyMsg  = "no matching hash family for %s"
 	InvalidHashFuncConfigMsg = "invalid hash configs: (family = %v, platform bit = %v, output bit = %v)"
+	KeyRequiredMsg           = "hash family %v is keyed, supply a key with WithKey"
+	KeyNotSupportedMsg       = "hash family %v is not keyed"
+	SecretNotSupportedMsg    = "hash family %v takes no secret (only xxh3Hash64 and xxh3Hash128)"
 )
 
 // errors in runtime
@@ -19,12 +25,18 @@ type HashOutType interface {
 }
 
 type HashFunction[T HashOutType] func([]byte, T) ([]T, error)
+
+// appends the hash of data to dst, lets hot loops hash without allocating
+type HashIntoFunction[T HashOutType] func(dst []T, data []byte, seed T) ([]T, error)
 type HashAttribute struct {
 	HashFamily  string
 	PlatformBit uint
 	OutputBit   uint
 }
 
+// guards the registries below, see Register
+var registryLock sync.RWMutex
+
 var unsignedInt32HashFunctions = map[HashAttribute]HashFunction[uint32]{}
 var unsignedInt64HashFunctions = map[HashAttribute]HashFunction[uint64]{
 	{"murmur3Hash128Default", 64, 128}:   murmur3Hash128Default,
@@ -33,9 +45,31 @@ var unsignedInt64HashFunctions = map[HashAttribute]HashFunction[uint64]{
 	{"murmur3Hash256Bnb", 64, 256}:       murmur3Hash256Bnb,
 	{"xxHashCespare", 64, 64}:            xxHash64Cespare,
 	{"xxHashOneOfOne", 64, 64}:           xxHash64OneOfOne,
+	{"xxh3Hash64", 64, 64}:               xxh3Hash64Default,
+	{"xxh3Hash128", 64, 128}:             xxh3Hash128Default,
+}
+
+// allocation-free forms of builtin families, the others are wrapped by hashIntoFunction
+var unsignedInt64HashIntoFunctions = map[HashAttribute]HashIntoFunction[uint64]{
+	{"murmur3Hash128Default", 64, 128}: murmur3Hash128DefaultInto,
+	{"murmur3Hash256Bnb", 64, 256}:     murmur3Hash256BnbInto,
+	{"xxh3Hash64", 64, 64}:             xxh3Hash64DefaultInto,
+	{"xxh3Hash128", 64, 128}:           xxh3Hash128DefaultInto,
+}
+
+// keyed families, instantiated per 128-bit key
+var unsignedInt32KeyedHashFunctions = map[HashAttribute]func(key [16]byte) HashFunction[uint32]{
+	{"halfSipHash24", 32, 32}: newHalfSipHash32,
+}
+var unsignedInt64KeyedHashFunctions = map[HashAttribute]func(key [16]byte) HashFunction[uint64]{
+	{"sipHash24", 64, 64}:  newSipHash64,
+	{"sipHash24", 64, 128}: newSipHash128,
 }
 
 func NewHashFunction[T HashOutType](family string, platformBit uint, outputBit uint) (HashFunction[T], error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
 	var genericRef T
 	hashAttr := HashAttribute{family, platformBit, outputBit}
 	typeName := fmt.Sprintf("%T", genericRef)
@@ -52,3 +86,65 @@ func NewHashFunction[T HashOutType](family string, platformBit uint, outputBit u
 	}
 	return nil, fmt.Errorf(InvalidHashFuncConfigMsg, family, platformBit, outputBit)
 }
+
+func isKeyedHashFunction[T HashOutType](family string, platformBit uint, outputBit uint) bool {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var genericRef T
+	hashAttr := HashAttribute{family, platformBit, outputBit}
+	typeName := fmt.Sprintf("%T", genericRef)
+
+	switch typeName {
+	case "uint64":
+		_, ok := unsignedInt64KeyedHashFunctions[hashAttr]
+		return ok
+	case "uint32":
+		_, ok := unsignedInt32KeyedHashFunctions[hashAttr]
+		return ok
+	}
+	return false
+}
+
+func NewKeyedHashFunction[T HashOutType](family string, platformBit uint, outputBit uint, key [16]byte) (HashFunction[T], error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var genericRef T
+	hashAttr := HashAttribute{family, platformBit, outputBit}
+	typeName := fmt.Sprintf("%T", genericRef)
+
+	switch typeName {
+	case "uint64":
+		if newHf, ok := unsignedInt64KeyedHashFunctions[hashAttr]; ok {
+			return any(newHf(key)).(HashFunction[T]), nil
+		}
+	case "uint32":
+		if newHf, ok := unsignedInt32KeyedHashFunctions[hashAttr]; ok {
+			return any(newHf(key)).(HashFunction[T]), nil
+		}
+	}
+	return nil, fmt.Errorf(InvalidHashFuncConfigMsg, family, platformBit, outputBit)
+}
+
+// HashIntoFunction of an unkeyed family, registered families are wrapped and still allocate per call
+func hashIntoFunction[T HashOutType](hashAttr HashAttribute, hashFunction HashFunction[T]) HashIntoFunction[T] {
+	var genericRef T
+	if fmt.Sprintf("%T", genericRef) == "uint64" {
+		if hf, ok := unsignedInt64HashIntoFunctions[hashAttr]; ok {
+			return any(hf).(HashIntoFunction[T])
+		}
+	}
+	return wrapHashFunction(hashFunction)
+}
+
+// appends the output of any hash function, keyed and secret ones included
+func wrapHashFunction[T HashOutType](hashFunction HashFunction[T]) HashIntoFunction[T] {
+	return func(dst []T, data []byte, seed T) ([]T, error) {
+		hashes, err := hashFunction(data, seed)
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, hashes...), nil
+	}
+}
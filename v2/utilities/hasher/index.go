@@ -0,0 +1,87 @@
+package hasher
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	InvalidIndexSizeMsg = "invalid index size %v for %v-bit hashes"
+)
+
+// how a hash is turned into a slot in [0, n)
+type IndexMode uint8
+
+const (
+	// (h * n) >> w for w-bit h, 1 multiplication instead of a division (Lemire, 2019, Fast Random Integer Generation in an Interval)
+	// uses the high bits of h, so hashes must span the whole range of T (hashCeil 0 or math.MaxUint)
+	MultiplyShiftIndex IndexMode = iota
+	// n is rounded up to a power of 2 and h & (n - 1) keeps the low bits
+	MaskIndex
+	// h % n
+	ModuloIndex
+)
+
+func (m IndexMode) String() string {
+	switch m {
+	case MultiplyShiftIndex:
+		return "multiply-shift"
+	case MaskIndex:
+		return "mask"
+	case ModuloIndex:
+		return "modulo"
+	}
+	return fmt.Sprintf("IndexMode(%d)", uint8(m))
+}
+
+// maps hashes of T to slots, shared by every structure that indexes an array with a hash
+type Indexer[T HashOutType] struct {
+	size  uint
+	mode  IndexMode
+	shift uint // 64 - bit width of T, moves h to the top of a 64-bit word
+}
+
+// smallest power of 2 >= n, 1 for n = 0
+func NextPowerOfTwo(n uint) uint {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(n-1)
+}
+
+// indexer over n slots, MaskIndex rounds n up, see Size
+func NewIndexer[T HashOutType](n uint, mode IndexMode) (Indexer[T], error) {
+	hashBits := uint(bits.Len64(uint64(^T(0))))
+	if mode == MaskIndex {
+		n = NextPowerOfTwo(n)
+	}
+	// every slot must be reachable by a hash
+	if n == 0 || (hashBits < 64 && uint64(n) > 1<<hashBits) {
+		return Indexer[T]{}, fmt.Errorf(InvalidIndexSizeMsg, n, hashBits)
+	}
+	return Indexer[T]{size: n, mode: mode, shift: 64 - hashBits}, nil
+}
+
+// number of slots, a power of 2 with MaskIndex
+func (ix Indexer[T]) Size() uint      { return ix.size }
+func (ix Indexer[T]) Mode() IndexMode { return ix.mode }
+
+// hashCeil to generate hashes with, multiply-shift needs them unreduced
+func (ix Indexer[T]) HashCeil() uint {
+	if ix.mode == MultiplyShiftIndex {
+		return math.MaxUint
+	}
+	return ix.size
+}
+
+func (ix Indexer[T]) Index(h T) uint {
+	switch ix.mode {
+	case MaskIndex:
+		return uint(h) & (ix.size - 1)
+	case ModuloIndex:
+		return uint(uint64(h) % uint64(ix.size))
+	}
+	hi, _ := bits.Mul64(uint64(h)<<ix.shift, uint64(ix.size))
+	return uint(hi)
+}
@@ -36,6 +36,10 @@ func fmix64(h uint64) uint64 {
 // hash into 128-bit representation of data
 // (64 MSBs in 1st 64-bit hash and 64 LSBs in 2nd 64-bit hash)
 func murmur3Hash128Default(data []byte, seed uint64) ([]uint64, error) {
+	return murmur3Hash128DefaultInto(make([]uint64, 0, 2), data, seed)
+}
+
+func murmur3Hash128DefaultInto(dst []uint64, data []byte, seed uint64) ([]uint64, error) {
 	dataLength := uint64(len(data))
 	// initialize 2 64-bit hash repr of final 128-bit output
 	h1 := seed
@@ -161,7 +165,7 @@ func murmur3Hash128Default(data []byte, seed uint64) ([]uint64, error) {
 	h1 += h2
 	h2 += h1
 
-	return []uint64{h1, h2}, nil
+	return append(dst, h1, h2), nil
 }
 
 func murmur3Hash128Spaolacci(data []byte, seed uint64) ([]uint64, error) {
@@ -184,7 +188,11 @@ func murmur3Hash64Spaolacci(data []byte, seed uint64) ([]uint64, error) {
 }
 
 func murmur3Hash256Bnb(data []byte, seed uint64) ([]uint64, error) {
+	return murmur3Hash256BnbInto(make([]uint64, 0, 4), data, seed)
+}
+
+func murmur3Hash256BnbInto(dst []uint64, data []byte, seed uint64) ([]uint64, error) {
 	var hf digest128
 	h1, h2, h3, h4 := hf.sum256(data)
-	return []uint64{h1, h2, h3, h4}, nil
+	return append(dst, h1, h2, h3, h4), nil
 }
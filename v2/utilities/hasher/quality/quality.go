@@ -0,0 +1,285 @@
+// statistical quality of a hash family combined with a generate method
+// avalanche and bit independence follow Webster & Tavares (1985), On the Design of S-Boxes
+// uniformity and in-key collisions measure the indices a structure would actually use
+package quality
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+
+	"github.com/nnurry/probabilistics/v2/utilities/hasher"
+)
+
+const (
+	InvalidConfigMsg     = "invalid quality config: (samples = %v, key length = %v, keys = %v, capacity = %v, hash num = %v)"
+	ExceededThresholdMsg = "%s: %s = %.4f > %.4f"
+)
+
+type Config struct {
+	// random keys flipped bit by bit for avalanche and bit independence
+	Samples int
+	// bytes per key
+	KeyLength int
+	// keys hashed for uniformity and collisions, counters 0, 1, ... written little endian, the hardest case for weak hashes
+	Keys int
+	// slots the indices fall into
+	Capacity uint
+	// hashes generated per key
+	HashNum uint
+	// index mapping, the same one structures use
+	IndexMode hasher.IndexMode
+	// seed of the generator and of the random keys
+	Seed int64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Samples:   1000,
+		KeyLength: 16,
+		Keys:      100000,
+		Capacity:  4096,
+		HashNum:   4,
+		IndexMode: hasher.MultiplyShiftIndex,
+	}
+}
+
+type Report struct {
+	Hash string
+	// output bits of 1 key, HashNum hashes of the bit width of T
+	OutputBits int
+
+	// bias of a cell = |2 P(output bit j flips | input bit i flips) - 1|, 0 for an ideal hash
+	MaxAvalancheBias  float64
+	MeanAvalancheBias float64
+
+	// |correlation| between flips of 2 output bits of the 1st hash, over every input bit flip
+	MaxBitIndependenceBias float64
+
+	// chi-squared of the index counts over Capacity slots
+	ChiSquared       float64
+	DegreesOfFreedom float64
+	// Wilson-Hilferty normal approximation, ~N(0, 1) for uniform indices
+	UniformityZ      float64
+	UniformityPValue float64
+
+	// fraction of index pairs of 1 key that collide, 1 / Capacity for independent indices
+	CollisionRate         float64
+	ExpectedCollisionRate float64
+}
+
+// CollisionRate / ExpectedCollisionRate, 1 for independent indices
+func (r Report) CollisionRatio() float64 {
+	if r.ExpectedCollisionRate == 0 {
+		return 0
+	}
+	return r.CollisionRate / r.ExpectedCollisionRate
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"%s: avalanche bias = %.4f (max %.4f), bit independence bias = %.4f, chi2 = %.1f (dof %.0f, z = %.2f), collisions = %.2fx",
+		r.Hash,
+		r.MeanAvalancheBias, r.MaxAvalancheBias,
+		r.MaxBitIndependenceBias,
+		r.ChiSquared, r.DegreesOfFreedom, r.UniformityZ,
+		r.CollisionRatio(),
+	)
+}
+
+// upper limits of a Report, 0 disables a check
+type Thresholds struct {
+	MeanAvalancheBias      float64
+	MaxAvalancheBias       float64
+	MaxBitIndependenceBias float64
+	UniformityZ            float64
+	CollisionRatio         float64
+}
+
+// limits an ideal hash stays under for cfg, set well above the sampling noise
+func DefaultThresholds(cfg Config) Thresholds {
+	samples := float64(cfg.Samples)
+	flips := samples * float64(cfg.KeyLength*8)
+	pairs := float64(cfg.Keys) * float64(cfg.HashNum) * float64(cfg.HashNum-1) / 2
+	expectedCollisions := pairs / float64(cfg.Capacity)
+	return Thresholds{
+		// E|N(0, 1 / n)| = sqrt(2 / (pi n))
+		MeanAvalancheBias: 1.25 * math.Sqrt(2/(math.Pi*samples)),
+		// max of thousands of cells, each ~N(0, 1 / n)
+		MaxAvalancheBias:       6 / math.Sqrt(samples),
+		MaxBitIndependenceBias: 8 / math.Sqrt(flips),
+		UniformityZ:            5,
+		// fewer collisions than independent indices is no defect
+		CollisionRatio: 1 + 6/math.Sqrt(max(expectedCollisions, 1)),
+	}
+}
+
+// every exceeded threshold, nil when the report is within all of them
+func (r Report) Check(t Thresholds) error {
+	errs := []error{}
+	check := func(name string, value, limit float64) {
+		if limit > 0 && value > limit {
+			errs = append(errs, fmt.Errorf(ExceededThresholdMsg, r.Hash, name, value, limit))
+		}
+	}
+	check("mean avalanche bias", r.MeanAvalancheBias, t.MeanAvalancheBias)
+	check("max avalanche bias", r.MaxAvalancheBias, t.MaxAvalancheBias)
+	check("bit independence bias", r.MaxBitIndependenceBias, t.MaxBitIndependenceBias)
+	check("uniformity z", r.UniformityZ, t.UniformityZ)
+	check("collision ratio", r.CollisionRatio(), t.CollisionRatio)
+	return errors.Join(errs...)
+}
+
+// build the generator of attr and method, then Analyze it
+func AnalyzeFamily[T hasher.HashOutType](attr hasher.HashAttribute, generateMethod string, cfg Config, opts ...hasher.HashOption) (*Report, error) {
+	g, err := hasher.NewHashGenerator[T](attr.HashFamily, attr.PlatformBit, attr.OutputBit, generateMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(g, cfg)
+}
+
+func Analyze[T hasher.HashOutType](g *hasher.HashGenerator[T], cfg Config) (*Report, error) {
+	if cfg.Samples <= 0 || cfg.KeyLength <= 0 || cfg.Keys <= 0 || cfg.Capacity < 2 || cfg.HashNum == 0 {
+		return nil, fmt.Errorf(InvalidConfigMsg, cfg.Samples, cfg.KeyLength, cfg.Keys, cfg.Capacity, cfg.HashNum)
+	}
+	ix, err := hasher.NewIndexer[T](cfg.Capacity, cfg.IndexMode)
+	if err != nil {
+		return nil, err
+	}
+
+	wordBits := bits.Len64(uint64(^T(0)))
+	report := &Report{Hash: g.String(), OutputBits: wordBits * int(cfg.HashNum)}
+	if err := avalanche(g, cfg, wordBits, report); err != nil {
+		return nil, err
+	}
+	if err := distribution(g, cfg, ix, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// flips every input bit of random keys, counts output bit flips and flip pairs of the 1st hash
+func avalanche[T hasher.HashOutType](g *hasher.HashGenerator[T], cfg Config, wordBits int, report *Report) error {
+	inputBits := cfg.KeyLength * 8
+	outputBits := report.OutputBits
+	flips := make([]uint32, inputBits*outputBits)
+	// pair counts of the 1st hash, pairFlips[j * wordBits + k] for j < k
+	pairFlips := make([]uint32, wordBits*wordBits)
+	bitFlips := make([]uint32, wordBits)
+	diffs := 0
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	seed := T(cfg.Seed)
+	key := make([]byte, cfg.KeyLength)
+	var base, flipped []T
+	var err error
+	for s := 0; s < cfg.Samples; s++ {
+		rng.Read(key)
+		if base, err = g.GenerateHashInto(base, key, seed, math.MaxUint, cfg.HashNum); err != nil {
+			return err
+		}
+		for i := 0; i < inputBits; i++ {
+			key[i/8] ^= 1 << (i % 8)
+			flipped, err = g.GenerateHashInto(flipped, key, seed, math.MaxUint, cfg.HashNum)
+			key[i/8] ^= 1 << (i % 8)
+			if err != nil {
+				return err
+			}
+
+			row := flips[i*outputBits:]
+			for w := range base {
+				d := uint64(base[w] ^ flipped[w])
+				for ; d != 0; d &= d - 1 {
+					row[w*wordBits+bits.TrailingZeros64(d)]++
+				}
+			}
+
+			d := uint64(base[0] ^ flipped[0])
+			for rest := d; rest != 0; rest &= rest - 1 {
+				j := bits.TrailingZeros64(rest)
+				bitFlips[j]++
+				for above := rest & (rest - 1); above != 0; above &= above - 1 {
+					pairFlips[j*wordBits+bits.TrailingZeros64(above)]++
+				}
+			}
+			diffs++
+		}
+	}
+
+	total := 0.0
+	for _, count := range flips {
+		bias := math.Abs(2*float64(count)/float64(cfg.Samples) - 1)
+		total += bias
+		report.MaxAvalancheBias = max(report.MaxAvalancheBias, bias)
+	}
+	report.MeanAvalancheBias = total / float64(len(flips))
+
+	n := float64(diffs)
+	for j := 0; j < wordBits; j++ {
+		pj := float64(bitFlips[j]) / n
+		for k := j + 1; k < wordBits; k++ {
+			pk := float64(bitFlips[k]) / n
+			pjk := float64(pairFlips[j*wordBits+k]) / n
+			variance := pj * (1 - pj) * pk * (1 - pk)
+			if variance == 0 {
+				// a bit that always or never flips depends on nothing
+				report.MaxBitIndependenceBias = 1
+				continue
+			}
+			correlation := (pjk - pj*pk) / math.Sqrt(variance)
+			report.MaxBitIndependenceBias = max(report.MaxBitIndependenceBias, math.Abs(correlation))
+		}
+	}
+	return nil
+}
+
+// indices of counter keys: chi-squared over the slots and collisions within each key
+func distribution[T hasher.HashOutType](g *hasher.HashGenerator[T], cfg Config, ix hasher.Indexer[T], report *Report) error {
+	slots := ix.Size()
+	counts := make([]uint32, slots)
+	indices := make([]uint, cfg.HashNum)
+	key := make([]byte, max(cfg.KeyLength, 8))
+	seed := T(cfg.Seed)
+	collisions := 0
+
+	var hashes []T
+	var err error
+	for i := 0; i < cfg.Keys; i++ {
+		binary.LittleEndian.PutUint64(key, uint64(i))
+		if hashes, err = g.GenerateHashInto(hashes, key[:cfg.KeyLength], seed, ix.HashCeil(), cfg.HashNum); err != nil {
+			return err
+		}
+		for a, hash := range hashes {
+			indices[a] = ix.Index(hash)
+			counts[indices[a]]++
+			for b := 0; b < a; b++ {
+				if indices[b] == indices[a] {
+					collisions++
+				}
+			}
+		}
+	}
+
+	expected := float64(cfg.Keys) * float64(cfg.HashNum) / float64(slots)
+	for _, count := range counts {
+		delta := float64(count) - expected
+		report.ChiSquared += delta * delta / expected
+	}
+	dof := float64(slots - 1)
+	report.DegreesOfFreedom = dof
+	// (chi2 / dof)^(1/3) ~ N(1 - 2 / 9dof, 2 / 9dof)
+	spread := 2 / (9 * dof)
+	report.UniformityZ = (math.Cbrt(report.ChiSquared/dof) - (1 - spread)) / math.Sqrt(spread)
+	report.UniformityPValue = 0.5 * math.Erfc(report.UniformityZ/math.Sqrt2)
+
+	pairs := float64(cfg.Keys) * float64(cfg.HashNum) * float64(cfg.HashNum-1) / 2
+	if pairs > 0 {
+		report.CollisionRate = float64(collisions) / pairs
+		report.ExpectedCollisionRate = 1 / float64(slots)
+	}
+	return nil
+}
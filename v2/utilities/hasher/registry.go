@@ -0,0 +1,130 @@
+package hasher
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// errors when registering hash functions
+const (
+	DuplicateHashFamilyMsg   = "hash family already registered: (family = %v, platform bit = %v, output bit = %v)"
+	NilHashFunctionMsg       = "nil hash function for %v"
+	UnsupportedHashOutMsg    = "unsupported hash output type %s (use uint32 or uint64)"
+	InvalidPlatformBitMsg    = "invalid platform bit (%v != %v bits of %s)"
+	InvalidOutputBitMsg      = "invalid output bit (%v is not a positive multiple of %v)"
+	OutputLengthMismatchMsg  = "hash family %v returned %v words, declared %v bits = %v words"
+	NotRegisteredMsg         = "hash family not added by Register: (family = %v, platform bit = %v, output bit = %v)"
+	registrationProbeMessage = "probabilistics registration probe"
+)
+
+// families added by Register, the only ones Unregister removes, guarded by registryLock
+var registeredFamilies = map[HashAttribute]bool{}
+
+// register fn as a hash family usable by NewHashFunction / NewHashGenerator and every builder
+// attr.PlatformBit must be the width of T and attr.OutputBit a multiple of it
+// fn is probed once here, and every call checks it returns OutputBit / PlatformBit words
+func Register[T HashOutType](attr HashAttribute, fn HashFunction[T]) error {
+	var genericRef T
+	typeName := fmt.Sprintf("%T", genericRef)
+	width := uint(0)
+	switch typeName {
+	case "uint64":
+		width = 64
+	case "uint32":
+		width = 32
+	default:
+		return fmt.Errorf(UnsupportedHashOutMsg, typeName)
+	}
+	if fn == nil {
+		return fmt.Errorf(NilHashFunctionMsg, attr.HashFamily)
+	}
+	if attr.PlatformBit != width {
+		return fmt.Errorf(InvalidPlatformBitMsg, attr.PlatformBit, width, typeName)
+	}
+	if attr.OutputBit == 0 || attr.OutputBit%width != 0 {
+		return fmt.Errorf(InvalidOutputBitMsg, attr.OutputBit, width)
+	}
+
+	words := int(attr.OutputBit / width)
+	checked := func(data []byte, seed T) ([]T, error) {
+		hashes, err := fn(data, seed)
+		if err != nil {
+			return nil, err
+		}
+		if len(hashes) != words {
+			return nil, fmt.Errorf(OutputLengthMismatchMsg, attr.HashFamily, len(hashes), attr.OutputBit, words)
+		}
+		return hashes, nil
+	}
+	if _, err := checked([]byte(registrationProbeMessage), 0); err != nil {
+		return err
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if registered(attr) {
+		return fmt.Errorf(DuplicateHashFamilyMsg, attr.HashFamily, attr.PlatformBit, attr.OutputBit)
+	}
+	switch typeName {
+	case "uint64":
+		unsignedInt64HashFunctions[attr] = any(HashFunction[T](checked)).(HashFunction[uint64])
+	case "uint32":
+		unsignedInt32HashFunctions[attr] = any(HashFunction[T](checked)).(HashFunction[uint32])
+	}
+	registeredFamilies[attr] = true
+	return nil
+}
+
+// remove a family added by Register, builtin families stay
+// generators made from it keep working, new ones can't be made
+func Unregister(attr HashAttribute) error {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if !registeredFamilies[attr] {
+		return fmt.Errorf(NotRegisteredMsg, attr.HashFamily, attr.PlatformBit, attr.OutputBit)
+	}
+	delete(unsignedInt64HashFunctions, attr)
+	delete(unsignedInt32HashFunctions, attr)
+	delete(registeredFamilies, attr)
+	return nil
+}
+
+// caller holds registryLock
+func registered(attr HashAttribute) bool {
+	_, ok64 := unsignedInt64HashFunctions[attr]
+	_, ok32 := unsignedInt32HashFunctions[attr]
+	_, keyed64 := unsignedInt64KeyedHashFunctions[attr]
+	_, keyed32 := unsignedInt32KeyedHashFunctions[attr]
+	return ok64 || ok32 || keyed64 || keyed32
+}
+
+// every registered family (keyed ones included), sorted by family, platform bit and output bit
+func Families() []HashAttribute {
+	registryLock.RLock()
+	families := []HashAttribute{}
+	for attr := range unsignedInt64HashFunctions {
+		families = append(families, attr)
+	}
+	for attr := range unsignedInt32HashFunctions {
+		families = append(families, attr)
+	}
+	for attr := range unsignedInt64KeyedHashFunctions {
+		families = append(families, attr)
+	}
+	for attr := range unsignedInt32KeyedHashFunctions {
+		families = append(families, attr)
+	}
+	registryLock.RUnlock()
+
+	slices.SortFunc(families, func(a, b HashAttribute) int {
+		return cmp.Or(
+			cmp.Compare(a.HashFamily, b.HashFamily),
+			cmp.Compare(a.PlatformBit, b.PlatformBit),
+			cmp.Compare(a.OutputBit, b.OutputBit),
+		)
+	})
+	return families
+}
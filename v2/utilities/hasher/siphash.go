@@ -0,0 +1,181 @@
+// SipHash-2-4 and HalfSipHash-2-4 (Aumasson & Bernstein, 2012), keyed PRFs
+// https://github.com/veorq/SipHash
+// an attacker who doesn't know the key can't craft colliding keys, unlike murmur3 or xxhash
+package hasher
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+type sipState struct {
+	v0, v1, v2, v3 uint64
+}
+
+func (s *sipState) round() {
+	s.v0 += s.v1
+	s.v1 = bits.RotateLeft64(s.v1, 13)
+	s.v1 ^= s.v0
+	s.v0 = bits.RotateLeft64(s.v0, 32)
+	s.v2 += s.v3
+	s.v3 = bits.RotateLeft64(s.v3, 16)
+	s.v3 ^= s.v2
+	s.v0 += s.v3
+	s.v3 = bits.RotateLeft64(s.v3, 21)
+	s.v3 ^= s.v0
+	s.v2 += s.v1
+	s.v1 = bits.RotateLeft64(s.v1, 17)
+	s.v1 ^= s.v2
+	s.v2 = bits.RotateLeft64(s.v2, 32)
+}
+
+// compress message and length tag with 2 rounds per word, return the state before finalization
+func sipHashCompress(data []byte, k0, k1 uint64, wide bool) sipState {
+	s := sipState{
+		v0: k0 ^ 0x736f6d6570736575,
+		v1: k1 ^ 0x646f72616e646f6d,
+		v2: k0 ^ 0x6c7967656e657261,
+		v3: k1 ^ 0x7465646279746573,
+	}
+	if wide {
+		s.v1 ^= 0xee
+	}
+
+	n := len(data) / 8 * 8
+	for i := 0; i < n; i += 8 {
+		m := binary.LittleEndian.Uint64(data[i:])
+		s.v3 ^= m
+		s.round()
+		s.round()
+		s.v0 ^= m
+	}
+
+	last := uint64(len(data)) << 56
+	for i, b := range data[n:] {
+		last |= uint64(b) << (8 * i)
+	}
+	s.v3 ^= last
+	s.round()
+	s.round()
+	s.v0 ^= last
+	return s
+}
+
+// 4 finalization rounds
+func (s *sipState) finalize(tag uint64) uint64 {
+	s.v2 ^= tag
+	for i := 0; i < 4; i++ {
+		s.round()
+	}
+	return s.v0 ^ s.v1 ^ s.v2 ^ s.v3
+}
+
+func sipHash24(data []byte, k0, k1 uint64) uint64 {
+	s := sipHashCompress(data, k0, k1, false)
+	return s.finalize(0xff)
+}
+
+// 128-bit output, words in output byte order
+func sipHash24Wide(data []byte, k0, k1 uint64) (uint64, uint64) {
+	s := sipHashCompress(data, k0, k1, true)
+	first := s.finalize(0xee)
+	s.v1 ^= 0xdd
+	second := s.finalize(0)
+	return first, second
+}
+
+func sipKey(key [16]byte, seed uint64) (uint64, uint64) {
+	// seed 0 is plain SipHash, other seeds give other members of the keyed family
+	return binary.LittleEndian.Uint64(key[:8]) ^ seed, binary.LittleEndian.Uint64(key[8:])
+}
+
+func newSipHash64(key [16]byte) HashFunction[uint64] {
+	return func(data []byte, seed uint64) ([]uint64, error) {
+		k0, k1 := sipKey(key, seed)
+		return []uint64{sipHash24(data, k0, k1)}, nil
+	}
+}
+
+func newSipHash128(key [16]byte) HashFunction[uint64] {
+	return func(data []byte, seed uint64) ([]uint64, error) {
+		k0, k1 := sipKey(key, seed)
+		first, second := sipHash24Wide(data, k0, k1)
+		return []uint64{first, second}, nil
+	}
+}
+
+// HalfSipHash works on 32-bit words with a 64-bit key
+
+type halfSipState struct {
+	v0, v1, v2, v3 uint32
+}
+
+func (s *halfSipState) round() {
+	s.v0 += s.v1
+	s.v1 = bits.RotateLeft32(s.v1, 5)
+	s.v1 ^= s.v0
+	s.v0 = bits.RotateLeft32(s.v0, 16)
+	s.v2 += s.v3
+	s.v3 = bits.RotateLeft32(s.v3, 8)
+	s.v3 ^= s.v2
+	s.v0 += s.v3
+	s.v3 = bits.RotateLeft32(s.v3, 7)
+	s.v3 ^= s.v0
+	s.v2 += s.v1
+	s.v1 = bits.RotateLeft32(s.v1, 13)
+	s.v1 ^= s.v2
+	s.v2 = bits.RotateLeft32(s.v2, 16)
+}
+
+func halfSipHash24(data []byte, k0, k1 uint32) uint32 {
+	s := halfSipState{v0: k0, v1: k1, v2: k0 ^ 0x6c796765, v3: k1 ^ 0x74656462}
+
+	n := len(data) / 4 * 4
+	for i := 0; i < n; i += 4 {
+		m := binary.LittleEndian.Uint32(data[i:])
+		s.v3 ^= m
+		s.round()
+		s.round()
+		s.v0 ^= m
+	}
+
+	last := uint32(len(data)) << 24
+	for i, b := range data[n:] {
+		last |= uint32(b) << (8 * i)
+	}
+	s.v3 ^= last
+	s.round()
+	s.round()
+	s.v0 ^= last
+
+	s.v2 ^= 0xff
+	for i := 0; i < 4; i++ {
+		s.round()
+	}
+	return s.v1 ^ s.v3
+}
+
+// HalfSipHash takes a 64-bit key: key[:8], key[8:] is ignored
+func newHalfSipHash32(key [16]byte) HashFunction[uint32] {
+	return func(data []byte, seed uint32) ([]uint32, error) {
+		k0 := binary.LittleEndian.Uint32(key[:4]) ^ seed
+		k1 := binary.LittleEndian.Uint32(key[4:8])
+		return []uint32{halfSipHash24(data, k0, k1)}, nil
+	}
+}
+
+// key bytes each keyed family reads
+var keySizes = map[string]int{
+	"sipHash24":     16,
+	"halfSipHash24": 8,
+}
+
+// public tag of a key so generators with different keys don't look alike (String, merge checks)
+// it is a SipHash output under the bytes of the key the family reads, it doesn't reveal the key
+func keyCheckValue(family string, key [16]byte) uint32 {
+	if n, ok := keySizes[family]; ok {
+		clear(key[n:])
+	}
+	k0, k1 := sipKey(key, 0)
+	return uint32(sipHash24([]byte("probabilistics key check"), k0, k1))
+}
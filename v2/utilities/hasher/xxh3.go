@@ -0,0 +1,407 @@
+// native XXH3 (xxHash v0.8), following Collet's reference implementation
+// https://github.com/Cyan4973/xxHash/blob/dev/xxhash.h
+package hasher
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	InvalidSecretSizeMsg = "invalid xxh3 secret size (%v < %v)"
+)
+
+const (
+	xxh3StripeLen          = 64
+	xxh3SecretConsumeRate  = 8
+	xxh3AccNb              = xxh3StripeLen / 8
+	xxh3SecretMergeStart   = 11
+	xxh3SecretLastAccStart = 7
+	xxh3MidSizeMax         = 240
+	xxh3MidStartOffset     = 3
+	xxh3MidLastOffset      = 17
+
+	// smallest custom secret accepted by XXH3
+	XXH3SecretSizeMin = 136
+)
+
+const (
+	xxhPrime32_1 uint64 = 0x9E3779B1
+	xxhPrime32_2 uint64 = 0x85EBCA77
+	xxhPrime32_3 uint64 = 0xC2B2AE3D
+
+	xxhPrime64_1 uint64 = 0x9E3779B185EBCA87
+	xxhPrime64_2 uint64 = 0xC2B2AE3D27D4EB4F
+	xxhPrime64_3 uint64 = 0x165667B19E3779F9
+	xxhPrime64_4 uint64 = 0x85EBCA77C2B2AE63
+	xxhPrime64_5 uint64 = 0x27D4EB2F165667C5
+)
+
+// kSecret, 192 pseudo-random bytes
+var xxh3DefaultSecret = []byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+func readU32(b []byte, offset int) uint64 {
+	return uint64(binary.LittleEndian.Uint32(b[offset:]))
+}
+
+func readU64(b []byte, offset int) uint64 {
+	return binary.LittleEndian.Uint64(b[offset:])
+}
+
+func xxh64Avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= xxhPrime64_2
+	h ^= h >> 29
+	h *= xxhPrime64_3
+	h ^= h >> 32
+	return h
+}
+
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= 0x165667919E3779F9
+	h ^= h >> 32
+	return h
+}
+
+// rrmxmx, stronger mix for 4-8 byte inputs
+func xxh3Rrmxmx(h uint64, length uint64) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= 0x9FB21C651E98DF25
+	h ^= (h >> 35) + length
+	h *= 0x9FB21C651E98DF25
+	h ^= h >> 28
+	return h
+}
+
+func mul128Fold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func xxh3Mix16(data []byte, offset int, secret []byte, secretOffset int, seed uint64) uint64 {
+	lo := readU64(data, offset) ^ (readU64(secret, secretOffset) + seed)
+	hi := readU64(data, offset+8) ^ (readU64(secret, secretOffset+8) - seed)
+	return mul128Fold64(lo, hi)
+}
+
+// secret derived from kSecret and seed, only used for inputs > 240 bytes
+func xxh3SeededSecret(base []byte, seed uint64) []byte {
+	if seed == 0 {
+		return base
+	}
+	secret := append([]byte(nil), base...)
+	for i := 0; i+16 <= len(base); i += 16 {
+		binary.LittleEndian.PutUint64(secret[i:], readU64(base, i)+seed)
+		binary.LittleEndian.PutUint64(secret[i+8:], readU64(base, i+8)-seed)
+	}
+	return secret
+}
+
+func xxh3Accumulate512(acc *[xxh3AccNb]uint64, data []byte, offset int, secret []byte, secretOffset int) {
+	for i := 0; i < xxh3AccNb; i++ {
+		value := readU64(data, offset+8*i)
+		key := value ^ readU64(secret, secretOffset+8*i)
+		acc[i^1] += value
+		acc[i] += (key & 0xFFFFFFFF) * (key >> 32)
+	}
+}
+
+func xxh3ScrambleAcc(acc *[xxh3AccNb]uint64, secret []byte, secretOffset int) {
+	for i := 0; i < xxh3AccNb; i++ {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= readU64(secret, secretOffset+8*i)
+		acc[i] = a * xxhPrime32_1
+	}
+}
+
+// stripes of 64 bytes go into 8 accumulators, the accumulators are scrambled after every block
+func xxh3HashLongLoop(data []byte, secret []byte) [xxh3AccNb]uint64 {
+	acc := [xxh3AccNb]uint64{
+		xxhPrime32_3, xxhPrime64_1, xxhPrime64_2, xxhPrime64_3,
+		xxhPrime64_4, xxhPrime32_2, xxhPrime64_5, xxhPrime32_1,
+	}
+	stripesPerBlock := (len(secret) - xxh3StripeLen) / xxh3SecretConsumeRate
+	blockLen := xxh3StripeLen * stripesPerBlock
+	blocks := (len(data) - 1) / blockLen
+
+	for b := 0; b < blocks; b++ {
+		for s := 0; s < stripesPerBlock; s++ {
+			xxh3Accumulate512(&acc, data, b*blockLen+s*xxh3StripeLen, secret, s*xxh3SecretConsumeRate)
+		}
+		xxh3ScrambleAcc(&acc, secret, len(secret)-xxh3StripeLen)
+	}
+
+	stripes := ((len(data) - 1) - blockLen*blocks) / xxh3StripeLen
+	for s := 0; s < stripes; s++ {
+		xxh3Accumulate512(&acc, data, blocks*blockLen+s*xxh3StripeLen, secret, s*xxh3SecretConsumeRate)
+	}
+	// last stripe always ends at the last byte
+	xxh3Accumulate512(&acc, data, len(data)-xxh3StripeLen, secret, len(secret)-xxh3StripeLen-xxh3SecretLastAccStart)
+	return acc
+}
+
+func xxh3MergeAccs(acc [xxh3AccNb]uint64, secret []byte, secretOffset int, start uint64) uint64 {
+	result := start
+	for i := 0; i < 4; i++ {
+		result += mul128Fold64(
+			acc[2*i]^readU64(secret, secretOffset+16*i),
+			acc[2*i+1]^readU64(secret, secretOffset+16*i+8),
+		)
+	}
+	return xxh3Avalanche(result)
+}
+
+// 64-bit
+
+func xxh3Hash64Short(data []byte, secret []byte, seed uint64) uint64 {
+	length := uint64(len(data))
+	switch {
+	case length > 8:
+		flip1 := (readU64(secret, 24) ^ readU64(secret, 32)) + seed
+		flip2 := (readU64(secret, 40) ^ readU64(secret, 48)) - seed
+		lo := readU64(data, 0) ^ flip1
+		hi := readU64(data, len(data)-8) ^ flip2
+		acc := length + bits.ReverseBytes64(lo) + hi + mul128Fold64(lo, hi)
+		return xxh3Avalanche(acc)
+	case length >= 4:
+		seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+		input := readU32(data, len(data)-4) + readU32(data, 0)<<32
+		flip := (readU64(secret, 8) ^ readU64(secret, 16)) - seed
+		return xxh3Rrmxmx(input^flip, length)
+	case length > 0:
+		combo := uint64(data[0])<<16 | uint64(data[len(data)>>1])<<24 | uint64(data[len(data)-1]) | length<<8
+		flip := (readU32(secret, 0) ^ readU32(secret, 4)) + seed
+		return xxh64Avalanche(combo ^ flip)
+	}
+	return xxh64Avalanche(seed ^ readU64(secret, 56) ^ readU64(secret, 64))
+}
+
+func xxh3Hash64Mid(data []byte, secret []byte, seed uint64) uint64 {
+	length := len(data)
+	acc := uint64(length) * xxhPrime64_1
+	if length <= 128 {
+		if length > 32 {
+			if length > 64 {
+				if length > 96 {
+					acc += xxh3Mix16(data, 48, secret, 96, seed)
+					acc += xxh3Mix16(data, length-64, secret, 112, seed)
+				}
+				acc += xxh3Mix16(data, 32, secret, 64, seed)
+				acc += xxh3Mix16(data, length-48, secret, 80, seed)
+			}
+			acc += xxh3Mix16(data, 16, secret, 32, seed)
+			acc += xxh3Mix16(data, length-32, secret, 48, seed)
+		}
+		acc += xxh3Mix16(data, 0, secret, 0, seed)
+		acc += xxh3Mix16(data, length-16, secret, 16, seed)
+		return xxh3Avalanche(acc)
+	}
+
+	rounds := length / 16
+	for i := 0; i < 8; i++ {
+		acc += xxh3Mix16(data, 16*i, secret, 16*i, seed)
+	}
+	acc = xxh3Avalanche(acc)
+	for i := 8; i < rounds; i++ {
+		acc += xxh3Mix16(data, 16*i, secret, 16*(i-8)+xxh3MidStartOffset, seed)
+	}
+	acc += xxh3Mix16(data, length-16, secret, XXH3SecretSizeMin-xxh3MidLastOffset, seed)
+	return xxh3Avalanche(acc)
+}
+
+// XXH3_64bits_withSecretandSeed: short inputs use secret and seed, long ones use longSecret
+func xxh3Hash64(data []byte, secret []byte, longSecret []byte, seed uint64) uint64 {
+	switch {
+	case len(data) <= 16:
+		return xxh3Hash64Short(data, secret, seed)
+	case len(data) <= xxh3MidSizeMax:
+		return xxh3Hash64Mid(data, secret, seed)
+	}
+	acc := xxh3HashLongLoop(data, longSecret)
+	return xxh3MergeAccs(acc, longSecret, xxh3SecretMergeStart, uint64(len(data))*xxhPrime64_1)
+}
+
+// 128-bit, (hi, lo)
+
+func xxh3Mix32(accLo, accHi uint64, data []byte, offset1, offset2 int, secret []byte, secretOffset int, seed uint64) (uint64, uint64) {
+	accLo += xxh3Mix16(data, offset1, secret, secretOffset, seed)
+	accLo ^= readU64(data, offset2) + readU64(data, offset2+8)
+	accHi += xxh3Mix16(data, offset2, secret, secretOffset+16, seed)
+	accHi ^= readU64(data, offset1) + readU64(data, offset1+8)
+	return accLo, accHi
+}
+
+func xxh3Hash128Short(data []byte, secret []byte, seed uint64) (uint64, uint64) {
+	length := uint64(len(data))
+	switch {
+	case length > 8:
+		flipLo := (readU64(secret, 32) ^ readU64(secret, 40)) - seed
+		flipHi := (readU64(secret, 48) ^ readU64(secret, 56)) + seed
+		inputLo := readU64(data, 0)
+		inputHi := readU64(data, len(data)-8)
+		mulHi, mulLo := bits.Mul64(inputLo^inputHi^flipLo, xxhPrime64_1)
+		mulLo += (length - 1) << 54
+		inputHi ^= flipHi
+		mulHi += inputHi + (inputHi&0xFFFFFFFF)*(xxhPrime32_2-1)
+		mulLo ^= bits.ReverseBytes64(mulHi)
+		resultHi, resultLo := bits.Mul64(mulLo, xxhPrime64_2)
+		resultHi += mulHi * xxhPrime64_2
+		return xxh3Avalanche(resultHi), xxh3Avalanche(resultLo)
+	case length >= 4:
+		seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+		input := readU32(data, 0) + readU32(data, len(data)-4)<<32
+		flip := (readU64(secret, 16) ^ readU64(secret, 24)) + seed
+		hi, lo := bits.Mul64(input^flip, xxhPrime64_1+length<<2)
+		hi += lo << 1
+		lo ^= hi >> 3
+		lo ^= lo >> 35
+		lo *= 0x9FB21C651E98DF25
+		lo ^= lo >> 28
+		return xxh3Avalanche(hi), lo
+	case length > 0:
+		combo := uint32(data[0])<<16 | uint32(data[len(data)>>1])<<24 | uint32(data[len(data)-1]) | uint32(length)<<8
+		comboHi := bits.RotateLeft32(bits.ReverseBytes32(combo), 13)
+		flipLo := (readU32(secret, 0) ^ readU32(secret, 4)) + seed
+		flipHi := (readU32(secret, 8) ^ readU32(secret, 12)) - seed
+		return xxh64Avalanche(uint64(comboHi) ^ flipHi), xxh64Avalanche(uint64(combo) ^ flipLo)
+	}
+	flipLo := readU64(secret, 64) ^ readU64(secret, 72)
+	flipHi := readU64(secret, 80) ^ readU64(secret, 88)
+	return xxh64Avalanche(seed ^ flipHi), xxh64Avalanche(seed ^ flipLo)
+}
+
+func xxh3Hash128Mid(data []byte, secret []byte, seed uint64) (uint64, uint64) {
+	length := len(data)
+	accLo, accHi := uint64(length)*xxhPrime64_1, uint64(0)
+	if length <= 128 {
+		if length > 32 {
+			if length > 64 {
+				if length > 96 {
+					accLo, accHi = xxh3Mix32(accLo, accHi, data, 48, length-64, secret, 96, seed)
+				}
+				accLo, accHi = xxh3Mix32(accLo, accHi, data, 32, length-48, secret, 64, seed)
+			}
+			accLo, accHi = xxh3Mix32(accLo, accHi, data, 16, length-32, secret, 32, seed)
+		}
+		accLo, accHi = xxh3Mix32(accLo, accHi, data, 0, length-16, secret, 0, seed)
+	} else {
+		rounds := length / 32
+		for i := 0; i < 4; i++ {
+			accLo, accHi = xxh3Mix32(accLo, accHi, data, 32*i, 32*i+16, secret, 32*i, seed)
+		}
+		accLo, accHi = xxh3Avalanche(accLo), xxh3Avalanche(accHi)
+		for i := 4; i < rounds; i++ {
+			accLo, accHi = xxh3Mix32(accLo, accHi, data, 32*i, 32*i+16, secret, xxh3MidStartOffset+32*(i-4), seed)
+		}
+		accLo, accHi = xxh3Mix32(accLo, accHi, data, length-16, length-32, secret, XXH3SecretSizeMin-xxh3MidLastOffset-16, -seed)
+	}
+	lo := accLo + accHi
+	hi := accLo*xxhPrime64_1 + accHi*xxhPrime64_4 + (uint64(length)-seed)*xxhPrime64_2
+	return -xxh3Avalanche(hi), xxh3Avalanche(lo)
+}
+
+func xxh3Hash128(data []byte, secret []byte, longSecret []byte, seed uint64) (uint64, uint64) {
+	switch {
+	case len(data) <= 16:
+		return xxh3Hash128Short(data, secret, seed)
+	case len(data) <= xxh3MidSizeMax:
+		return xxh3Hash128Mid(data, secret, seed)
+	}
+	acc := xxh3HashLongLoop(data, longSecret)
+	lo := xxh3MergeAccs(acc, longSecret, xxh3SecretMergeStart, uint64(len(data))*xxhPrime64_1)
+	hi := xxh3MergeAccs(acc, longSecret, len(longSecret)-8*xxh3AccNb-xxh3SecretMergeStart, ^(uint64(len(data)) * xxhPrime64_2))
+	return hi, lo
+}
+
+// seeded XXH3 with the default secret
+
+func xxh3Hash64Default(data []byte, seed uint64) ([]uint64, error) {
+	return xxh3Hash64DefaultInto(make([]uint64, 0, 1), data, seed)
+}
+
+func xxh3Hash64DefaultInto(dst []uint64, data []byte, seed uint64) ([]uint64, error) {
+	longSecret := xxh3DefaultSecret
+	if len(data) > xxh3MidSizeMax {
+		longSecret = xxh3SeededSecret(xxh3DefaultSecret, seed)
+	}
+	return append(dst, xxh3Hash64(data, xxh3DefaultSecret, longSecret, seed)), nil
+}
+
+// 128 bits as (64 MSBs, 64 LSBs) like the other 128-bit families
+func xxh3Hash128Default(data []byte, seed uint64) ([]uint64, error) {
+	return xxh3Hash128DefaultInto(make([]uint64, 0, 2), data, seed)
+}
+
+func xxh3Hash128DefaultInto(dst []uint64, data []byte, seed uint64) ([]uint64, error) {
+	longSecret := xxh3DefaultSecret
+	if len(data) > xxh3MidSizeMax {
+		longSecret = xxh3SeededSecret(xxh3DefaultSecret, seed)
+	}
+	hi, lo := xxh3Hash128(data, xxh3DefaultSecret, longSecret, seed)
+	return append(dst, hi, lo), nil
+}
+
+// XXH3 keyed by a custom secret of at least XXH3SecretSizeMin bytes (ideally random)
+// seed 0 gives XXH3_64bits_withSecret / XXH3_128bits_withSecret
+// any other seed perturbs the secret the same way XXH3 derives its seeded secret,
+// so generate methods that vary the seed still get independent hashes for long inputs
+func NewXXH3SecretHashFunction(secret []byte, outputBit uint) (HashFunction[uint64], error) {
+	if len(secret) < XXH3SecretSizeMin {
+		return nil, fmt.Errorf(InvalidSecretSizeMsg, len(secret), XXH3SecretSizeMin)
+	}
+	if outputBit != 64 && outputBit != 128 {
+		return nil, fmt.Errorf(InvalidHashFuncConfigMsg, "xxh3 with secret", 64, outputBit)
+	}
+	secret = append([]byte(nil), secret...)
+	return func(data []byte, seed uint64) ([]uint64, error) {
+		// seeded short paths mix the seed in, the long path needs it in the secret
+		longSecret := secret
+		if len(data) > xxh3MidSizeMax {
+			longSecret = xxh3SeededSecret(secret, seed)
+		}
+		if outputBit == 64 {
+			return []uint64{xxh3Hash64(data, secret, longSecret, seed)}, nil
+		}
+		hi, lo := xxh3Hash128(data, secret, longSecret, seed)
+		return []uint64{hi, lo}, nil
+	}, nil
+}
+
+// xxh3 families of NewHashGenerator that take WithSecret
+func isSecretHashFamily(attr HashAttribute) bool {
+	return attr == HashAttribute{"xxh3Hash64", 64, 64} || attr == HashAttribute{"xxh3Hash128", 64, 128}
+}
+
+// NewXXH3SecretHashFunction of a secret family, only for uint64 hashes
+func newSecretHashFunction[T HashOutType](attr HashAttribute, secret []byte) (HashFunction[T], error) {
+	var genericRef T
+	if fmt.Sprintf("%T", genericRef) != "uint64" || !isSecretHashFamily(attr) {
+		return nil, fmt.Errorf(InvalidHashFuncConfigMsg, attr.HashFamily, attr.PlatformBit, attr.OutputBit)
+	}
+	hf, err := NewXXH3SecretHashFunction(secret, attr.OutputBit)
+	if err != nil {
+		return nil, err
+	}
+	return any(hf).(HashFunction[T]), nil
+}
+
+// check value of a secret, the secret itself is never printed
+func secretCheckValue(secret []byte) uint32 {
+	return uint32(sipHash24(secret, 0, 0))
+}
@@ -1,12 +1,22 @@
 package hasher
 
 import (
+	"encoding/binary"
+
 	oneOfOneXxHash "github.com/OneOfOne/xxhash"
 	cespareXxHash "github.com/cespare/xxhash"
 )
 
+// cespare/xxhash v1 has no seeded digest, a seed != 0 is hashed as an 8-byte little-endian prefix of data
+// so multi-hash generate methods don't repeat 1 index
+// seed 0 is plain XXH64 of data, as before seeds were supported, so single-hash structures keep their hashes
 func xxHash64Cespare(data []byte, seed uint64) ([]uint64, error) {
 	hf := cespareXxHash.New()
+	if seed != 0 {
+		var prefix [8]byte
+		binary.LittleEndian.PutUint64(prefix[:], seed)
+		hf.Write(prefix[:])
+	}
 	_, err := hf.Write(data)
 	if err != nil {
 		return nil, err
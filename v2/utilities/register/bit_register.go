@@ -98,8 +98,8 @@ func (r *BitRegister) Write(offset uint, value uint) (oldValue uint, err error)
 		container |= helperValue
 	} else {
 		// clear bit
-		// xx1xx & 11011 = xx0xx
-		container &= helperValue
+		// xx1xx &^ 00100 = xx0xx
+		container &^= helperValue
 	}
 
 	r.containers[containerOffset] = container
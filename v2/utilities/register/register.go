@@ -113,3 +113,10 @@ func NewRegister(capacity, bitWidth uint) (r Register, err error) {
 
 	return r, err
 }
+
+func GetByteSize(r Register) uint {
+	// every register packs its cells into IntSize-bit containers
+	totalBits := r.Capacity() * r.BitWidth()
+	totalContainers := (totalBits + arch.IntSize - 1) / arch.IntSize
+	return totalContainers * (arch.IntSize / 8)
+}